@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/lint"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// defaultBaseline is where the baseline of the lint findings
+// lives (relative to the working directory) unless specified.
+const defaultBaseline = ".hugo-utils-baseline.json"
+
+var baselineFlag = cli.StringFlag{
+	Name:  "baseline",
+	Usage: "path to the baseline file",
+	Value: defaultBaseline,
+}
+
+// baselineFlags are the flags of the subcommands of 'baseline',
+// which lint the pages just like 'lint' does.
+var baselineFlags = []cli.Flag{
+	baselineFlag,
+	directoryFlag,
+	contentTypesFlag,
+	ruleFlag,
+	gitFlag,
+	workersFlag,
+	keepGoingFlag,
+	cacheDirFlag,
+	noCacheFlag,
+}
+
+var Baseline = cli.Command{
+	Name:  "baseline",
+	Usage: "manages the baseline of lint findings.",
+	Description: `A baseline is a file (meant to be committed along with the site)
+   that holds lint findings that are known, so that 'lint --baseline'
+   only reports (and fails on) the ones that are new. This allows
+   adopting rules without fixing every legacy page at once.
+
+   Known findings are matched by rule, file, front matter key and
+   message, but not by line, so that pages can still be edited.
+
+   'create' snapshots every current finding into the baseline
+   (replacing it), while 'prune' removes the findings of the
+   baseline that have since been fixed, never adding new ones.
+
+EXAMPLES:
+
+   Snapshot the current findings and then fail only on new ones:
+
+     hugo-utils baseline create
+     hugo-utils lint --baseline .hugo-utils-baseline.json
+
+   Remove the findings that got fixed from the baseline:
+
+     hugo-utils baseline prune`,
+	Subcommands: []cli.Command{
+		{
+			Name:   "create",
+			Usage:  "snapshots the current lint findings into the baseline.",
+			Action: withProject(baselineCreateAction),
+			Flags:  baselineFlags,
+		},
+		{
+			Name:   "prune",
+			Usage:  "removes the findings that got fixed from the baseline.",
+			Action: withProject(baselinePruneAction),
+			Flags:  baselineFlags,
+		},
+	},
+}
+
+func baselineCreateAction(c *cli.Context) (err error) {
+	pages, findings, err := lintSitePages(c, "create")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	parsed := len(pages)
+
+	baseline := lint.NewBaseline(c.String("baseline"), findings)
+
+	err = baseline.Save()
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	fmt.Printf("%d finding(s) saved to %s\n", len(findings), c.String("baseline"))
+
+	if partial {
+		showGatherErrors(gatherErr, parsed)
+		err = cli.NewExitError("", 1)
+		return
+	}
+
+	return
+}
+
+func baselinePruneAction(c *cli.Context) (err error) {
+	baseline, err := lint.LoadBaseline(c.String("baseline"))
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	pages, findings, err := lintSitePages(c, "prune")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	parsed := len(pages)
+
+	if partial {
+		// the findings of the pages that failed to be
+		// parsed would look fixed.
+		showGatherErrors(gatherErr, parsed)
+		err = cli.NewExitError("the baseline can't be pruned when pages fail to be parsed", 1)
+		return
+	}
+
+	pruned := baseline.Prune(findings)
+
+	err = baseline.Save()
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	fmt.Printf("%d fixed finding(s) pruned from %s (%d left)\n",
+		pruned, c.String("baseline"), len(baseline.Findings))
+	return
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/site"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// defaultCacheDir is where the parsed pages get cached
+// (relative to the root of the site) unless specified.
+const defaultCacheDir = ".hugo-utils/cache"
+
+var cacheDirFlag = cli.StringFlag{
+	Name:  "cache-dir",
+	Usage: "path to the directory where parsed pages are cached (defaults to .hugo-utils/cache under the site root)",
+}
+
+var Cache = cli.Command{
+	Name:  "cache",
+	Usage: "manages the cache of parsed pages.",
+	Description: `Commands that go through every page (e.g., 'list') keep the
+   front matter of the pages they parse in a cache (--cache-dir), so
+   that pages that didn't change (same size and modification time, or
+   same content) don't need to be parsed again in the next runs.
+
+   Unless specified, the cache lives under the root of the site
+   ('.hugo-utils/cache'), and pages out of any site aren't cached.
+   Failing to read or write the cache is only a warning: pages
+   then get parsed as if there was no cache.`,
+	Subcommands: []cli.Command{
+		{
+			Name:   "clean",
+			Usage:  "removes the cache of parsed pages.",
+			Action: withProject(cacheCleanAction),
+			Flags: []cli.Flag{
+				directoryFlag,
+				cacheDirFlag,
+			},
+		},
+	},
+}
+
+func cacheCleanAction(c *cli.Context) (err error) {
+	config, err := loadSite(c.String("directory"))
+	if err != nil && err != site.ErrSiteNotFound {
+		err = exitError(err)
+		return
+	}
+
+	dir := siteCacheDir(c, config)
+	if dir == "" {
+		cli.ShowCommandHelp(c, "clean")
+		err = cli.NewExitError(
+			"a cache directory must be specified (no hugo site found)", 1)
+		return
+	}
+
+	err = hugo.CleanPageCache(dir)
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/lint"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Collisions = cli.Command{
+	Name:  "collisions",
+	Usage: "finds pages that get published at the same URL.",
+	Description: `The 'collisions' command computes the URL that each content
+   page gets published at (its permalink, as shown by 'list') as well
+   as the URLs that redirect to it ('aliases'), then reports every URL
+   that more than one page claims, along with the files of those pages.
+
+   Aliases that don't start with '/' are relative to the directory of
+   the permalink of the page, just like in Hugo.
+
+   It exits non-zero when any collision is found, so that it can be
+   used to gate changes to a site (e.g., in CI).
+
+   With '--output' (json, sarif, checkstyle or junit), each claim
+   of a colliding URL gets reported as a finding of the
+   'url-collision' rule instead, pointing at the line of the
+   front matter that the URL comes from ('url', 'slug' or
+   'aliases', if any), just like 'lint' does.
+
+EXAMPLES:
+
+   Check the pages of the site that the working directory
+   belongs to:
+
+     hugo-utils collisions
+
+   Output when two posts end up with the same slug:
+
+     /posts/my-post/
+         permalink    content/posts/my-post.md
+         permalink    content/posts/my-post-draft.md
+
+     1 URL collision(s) found
+`,
+	Action: withProject(collisionsAction),
+	Flags: []cli.Flag{
+		directoryFlag,
+		contentTypesFlag,
+		workersFlag,
+		outputFlag,
+		keepGoingFlag,
+		cacheDirFlag,
+		noCacheFlag,
+	},
+}
+
+func showCollisions(collisions []*hugo.URLCollision) {
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
+	for _, collision := range collisions {
+		fmt.Fprintf(w, "%s\n", collision.URL)
+		for _, claim := range collision.Claims {
+			kind := "permalink"
+			if claim.Alias {
+				kind = "alias"
+			}
+
+			fmt.Fprintf(w, "\t%s\t%s\n", kind, claim.Page.Path)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	w.Flush()
+}
+
+// collisionFindings describes each claim of the URLs that
+// collide as a finding, pointing at the front matter key
+// that the URL comes from (if any).
+func collisionFindings(collisions []*hugo.URLCollision) (findings []lint.Finding) {
+	for _, collision := range collisions {
+		for _, claim := range collision.Claims {
+			var (
+				kind   = "permalink"
+				keys   = []string{"url", "slug"}
+				others []string
+			)
+
+			if claim.Alias {
+				kind, keys = "alias", []string{"aliases"}
+			}
+
+			for _, other := range collision.Claims {
+				if other.Page != claim.Page {
+					others = append(others, other.Page.Path)
+				}
+			}
+
+			finding := lint.Finding{
+				Rule:     "url-collision",
+				Severity: lint.SeverityError,
+				Path:     claim.Page.Path,
+				Line:     1,
+				Message: fmt.Sprintf("%s %s is also claimed by %s",
+					kind, collision.URL, strings.Join(others, ", ")),
+			}
+
+			for _, key := range keys {
+				if line := claim.Page.KeyLine(key); line != 0 {
+					finding.Key, finding.Line = key, line
+					break
+				}
+			}
+
+			findings = append(findings, finding)
+		}
+	}
+
+	return
+}
+
+func collisionsAction(c *cli.Context) (err error) {
+	format, err := lint.ParseReportFormat(c.String("output"))
+	if err != nil {
+		cli.ShowCommandHelp(c, "collisions")
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	_, pages, err := gatherSitePages(c, "collisions")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	parsed := len(pages)
+
+	collisions := hugo.FindURLCollisions(pages)
+	if format == lint.ReportFormatText {
+		showCollisions(collisions)
+	} else {
+		err = lint.WriteReport(os.Stdout, format, collisionFindings(collisions))
+		if err != nil {
+			err = exitError(err)
+			return
+		}
+	}
+
+	if partial {
+		showGatherErrors(gatherErr, parsed)
+	}
+
+	if len(collisions) > 0 {
+		err = cli.NewExitError(fmt.Sprintf(
+			"%d URL collision(s) found", len(collisions)), 1)
+		return
+	}
+
+	if partial {
+		err = cli.NewExitError("", 1)
+		return
+	}
+
+	return
+}
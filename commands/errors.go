@@ -0,0 +1,18 @@
+package commands
+
+import (
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// exitError converts an error into an error that makes the
+// CLI exit with a non-zero code, rendering page parse errors
+// along with the snippet of the page that caused them.
+func exitError(err error) *cli.ExitError {
+	if parseErr, ok := errors.Cause(err).(*hugo.ParseError); ok {
+		return cli.NewExitError(parseErr.Pretty(), 1)
+	}
+
+	return cli.NewExitError(err, 1)
+}
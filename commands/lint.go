@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/lint"
+	"github.com/cirocosta/hugo-utils/project"
+	"github.com/cirocosta/hugo-utils/site"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Lint = cli.Command{
+	Name:  "lint",
+	Usage: "checks that the pages follow the content rules of the site.",
+	Description: `The 'lint' command goes through the front matter of each content
+   page (just like 'list' does) and checks it against a set of rules
+   (e.g., every post must be tagged), reporting each problem found
+   as '<file>:<line>: <severity>: <message> (<rule>)', where the line
+   is the one of the front matter key that the problem is about.
+
+   Each rule has a severity ('error', 'warning' or 'info') that can be
+   changed, or the rule disabled altogether ('off'), with '--rule'
+   (e.g., '--rule missing-keywords=off') or in the '[params.lint]'
+   table of the site configuration, which '--rule' takes precedence
+   over:
+
+     [params.lint]
+       missing-keywords = "off"
+       missing-description = "error"
+
+   Rules can also be configured in the project configuration file
+   ('.hugo-utils.yaml' at the site root, or '~/.hugo-utils.yaml'),
+   which takes precedence over the site configuration:
+
+     lint:
+       rules:
+         missing-keywords: "off"
+
+   Use '--list-rules' to see every rule along with its severity.
+
+   Rules about content (missing titles, tags, ...) leave the pages
+   that list sections ('_index.md') out.
+
+   Findings that are known (e.g., those of legacy pages) can be left
+   out with '--baseline', which points at the file that 'hugo-utils
+   baseline create' snapshots them into: only new findings get
+   reported and make the command fail.
+
+   Findings can also be reported in formats that CI systems and
+   editors understand with '--output': 'json', 'sarif' (e.g., for
+   code scanning annotations), 'checkstyle' and 'junit'.
+
+   It exits non-zero when any finding has the 'error' severity, so
+   that it can be used to gate changes to a site (e.g., in CI).
+
+EXAMPLES:
+
+   Check the pages of the site that the working directory
+   belongs to:
+
+     hugo-utils lint
+
+   Output when a post has neither tags nor a description:
+
+     content/posts/my-post.md:1: warning: missing description (missing-description)
+     content/posts/my-post.md:1: error: missing tags (missing-tags)
+
+     1 error(s), 1 warning(s), 0 info(s)
+
+   Make missing keywords fail the check:
+
+     hugo-utils lint --rule missing-keywords=error
+
+   Report the findings as SARIF:
+
+     hugo-utils lint --output sarif > lint.sarif
+`,
+	Action: withProject(lintAction),
+	Flags: []cli.Flag{
+		directoryFlag,
+		contentTypesFlag,
+		ruleFlag,
+		cli.BoolFlag{
+			Name:  "list-rules",
+			Usage: "list the rules (with the severities they get) instead of checking pages",
+		},
+		cli.StringFlag{
+			Name:  "baseline",
+			Usage: "path to a baseline file whose findings are not reported (see 'hugo-utils baseline')",
+		},
+		outputFlag,
+		gitFlag,
+		workersFlag,
+		keepGoingFlag,
+		cacheDirFlag,
+		noCacheFlag,
+	},
+}
+
+var ruleFlag = cli.StringSliceFlag{
+	Name:  "rule",
+	Usage: "severity of a rule (error, warning, info or off), as '<rule>=<severity>'",
+}
+
+// lintSeverities retrieves the severities that the rules are
+// configured with, taken from the `[params.lint]` table of the
+// site configuration (if any), then from the `lint.rules` of the
+// project configuration and then from `--rule` flags.
+func lintSeverities(config *site.Config, proj *project.Config, flags []string) (severities map[string]lint.Severity, err error) {
+	var settings = map[string]string{}
+
+	if config != nil {
+		params, _ := config.Raw["params"].(map[string]interface{})
+		table, _ := params["lint"].(map[string]interface{})
+
+		for id, value := range table {
+			settings[id] = fmt.Sprint(value)
+		}
+	}
+
+	for id, value := range proj.Lint.Rules {
+		settings[id] = value
+	}
+
+	for _, flag := range flags {
+		parts := strings.SplitN(flag, "=", 2)
+		if len(parts) != 2 {
+			err = errors.Errorf(
+				"malformed rule setting %s (expected '<rule>=<severity>')", flag)
+			return
+		}
+
+		settings[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	severities = map[string]lint.Severity{}
+	for id, name := range settings {
+		severities[id], err = lint.ParseSeverity(name)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid setting of lint rule %s", id)
+			return
+		}
+	}
+
+	return
+}
+
+func showLintRules(severities map[string]lint.Severity) {
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
+	for _, rule := range lint.Rules() {
+		severity, ok := severities[rule.ID]
+		if !ok {
+			severity = rule.Severity
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", rule.ID, severity, rule.Description)
+	}
+	w.Flush()
+}
+
+// lintSitePages lints the pages of the site (or of the directory)
+// that a command is pointed at, as told by the flags of the command.
+//
+// With '--keep-going', the pages that could be parsed (and their
+// findings) are returned along with a `*hugo.GatherError`.
+func lintSitePages(c *cli.Context, command string) (pages []*hugo.Page, findings []lint.Finding, err error) {
+	config, pages, err := gatherSitePages(c, command)
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	severities, err := lintSeverities(config, projectConfig(c), c.StringSlice("rule"))
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	findings, err = lint.Lint(pages, lint.Options{Severities: severities})
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if partial {
+		err = gatherErr
+	}
+
+	return
+}
+
+func lintAction(c *cli.Context) (err error) {
+	if c.Bool("list-rules") {
+		var (
+			config     *site.Config
+			severities map[string]lint.Severity
+		)
+
+		config, err = loadSite(c.String("directory"))
+		if err != nil && err != site.ErrSiteNotFound {
+			err = exitError(err)
+			return
+		}
+
+		severities, err = lintSeverities(config, projectConfig(c), c.StringSlice("rule"))
+		if err != nil {
+			err = cli.NewExitError(err, 1)
+			return
+		}
+
+		showLintRules(severities)
+		return
+	}
+
+	format, err := lint.ParseReportFormat(c.String("output"))
+	if err != nil {
+		cli.ShowCommandHelp(c, "lint")
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	pages, findings, err := lintSitePages(c, "lint")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	parsed := len(pages)
+
+	if path := c.String("baseline"); path != "" {
+		var (
+			baseline *lint.Baseline
+			known    int
+		)
+
+		baseline, err = lint.LoadBaseline(path)
+		if err != nil {
+			err = exitError(err)
+			return
+		}
+
+		findings, known = baseline.Filter(findings)
+		fmt.Fprintf(os.Stderr, "%d finding(s) known by baseline %s\n", known, path)
+	}
+
+	err = lint.WriteReport(os.Stdout, format, findings)
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	if partial {
+		showGatherErrors(gatherErr, parsed)
+	}
+
+	if errs := lint.Count(findings, lint.SeverityError); errs > 0 {
+		err = cli.NewExitError(fmt.Sprintf(
+			"%d lint error(s) found", errs), 1)
+		return
+	}
+
+	if partial {
+		err = cli.NewExitError("", 1)
+		return
+	}
+
+	return
+}
@@ -5,28 +5,107 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strings"
 	"text/tabwriter"
 	"text/template"
+	"time"
 
 	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/site"
+	"github.com/pkg/errors"
 	"gopkg.in/urfave/cli.v1"
 )
 
 var List = cli.Command{
 	Name:  "list",
 	Usage: "lists all content under a given path.",
-	Description: `The 'list' command iterates over each content file (*.md)
-   found under a given root directory (--directory), then prints
-   to 'stdout' a description of each.
+	Description: `The 'list' command iterates over each content file (*.md,
+   *.markdown, *.html, *.adoc, *.org, *.rst, *.pandoc, ...) found under
+   a given root directory (--directory), then prints to 'stdout' a
+   description of each.
+
+   When no directory is given, the content directory of the Hugo
+   site that the working directory belongs to is used (the site
+   is found by looking for its 'hugo.toml', 'config.yaml', etc,
+   from the working directory up). The site configuration also
+   determines the sections of the pages and the files to ignore
+   ('ignoreFiles').
+
+   The extensions considered as content can be restricted (or custom
+   ones added) with '--content-types' (e.g., 'md,html,txt=markdown').
+   Pages of markups other than Markdown (e.g., bare '.html' files)
+   can go without front matter, as Hugo renders them as they are.
 
    The default formatting displays the following attributes for
-   each page: title, keywords, tags, categories, slug, date.
+   each page: title, file, permalink, slug, date, last-mod,
+   publish-date, expiry-date, keywords, tags, draft.
+
+   Dates are the ones that Hugo uses: besides the 'date', 'lastmod',
+   'publishDate' and 'expiryDate' entries of the front matter, they
+   follow the site's '[frontmatter]' configuration, which can take
+   them from other entries, from the date that prefixes the file name
+   (':filename') or from the modification time of the file
+   (':fileModTime'). These are the dates that '--sort' uses and that
+   templates get through {{ .Dates }} (e.g., {{ .Dates.PublishDate }}),
+   while {{ .Date }} and friends hold what the front matter says.
+   Dates that aren't set are left blank.
+
+   As with Hugo, pages scheduled to be published in the future and
+   pages that expired are left out, unless '--future' and '--expired'
+   (respectively) bring them back.
+
+   With '--git' (or 'enableGitInfo' in the site configuration), the
+   last modification date of each page is the date of the last
+   commit that touched it (unless '[frontmatter]' says otherwise),
+   and the author of that commit is displayed too. As with 'update
+   --lastmod-from-git', commits that only changed 'lastmod' don't
+   count. Templates get the commit through {{ .GitInfo }} (e.g.,
+   {{ .GitInfo.AuthorName }}), which is empty for files without
+   history.
 
    A custom format can also be specified following Go template
    rules. In this case, the render state contains:
    - {{ . }}: the current page in the page traversal; and
    - {{ .Pages }}: the list of all pages found.
 
+   Besides pages, the terms of any taxonomy can be listed (e.g.,
+   '--type=tags' or '--type=series'), each with the number of pages
+   classified with it and those pages. The taxonomies are the ones
+   configured for the site ('taxonomies'), or 'tags' and 'categories'
+   when there's none; '--taxonomies' overrides them. Terms are
+   taken from the front matter entry named after the taxonomy,
+   regardless of case. With a custom format, the render state
+   contains:
+   - {{ .Name }}: the current term in the term traversal;
+   - {{ .Count }} and {{ .Pages }}: the pages classified with it; and
+   - {{ .Taxonomy }}: the taxonomy (with all of its {{ .Taxonomy.Terms }}).
+
+   Besides its front matter, each page carries its bundle kind
+   ({{ .Kind }}: single, leaf for 'index.md' or branch for
+   '_index.md'), its section ({{ .Section }}), the files of
+   its bundle ({{ .Resources }}), the markup its body is written
+   in ({{ .Markup }}), its language ({{ .Language }}) and the URL
+   that it gets published at, relative to the base URL of the site
+   ({{ .Permalink }}). Permalinks follow the site's 'permalinks'
+   patterns (':year', ':month', ':day', ':section', ':title',
+   ':slug', ':filename', ...), the 'url' and 'slug' of the front
+   matter and the language prefixes of multilingual sites.
+
+   Formats can also be kept in the project configuration file
+   ('.hugo-utils.yaml' at the site root, or '~/.hugo-utils.yaml')
+   as named templates, which '--template' refers to:
+
+     templates:
+       untagged: '{{ if eq (len .Tags) 0 }}{{ .Path }}{{ end }}'
+
+   Only the front matter of each page is read up front; the
+   body of a page ({{ .Body }}) is read when a format uses it.
+
+   Parsed front matter is cached under '--cache-dir' so that pages
+   that didn't change aren't parsed again in the next runs (see
+   'hugo-utils cache clean'). Use '--no-cache' to always parse
+   every page.
+
 EXAMPLES:
 
    Display every property of the pages under a given
@@ -49,28 +128,70 @@ EXAMPLES:
      hugo-utils \
        --directory=./content/blog \
        '{{ if eq (len .Keywords) 0 }} {{ .Path }} {{ end }}'
+
+   Display the posts (leaving the sections' '_index.md' out) along
+   with the resources of their bundles:
+
+     hugo-utils \
+       --directory=./content \
+       --kind=single,leaf \
+       '{{ .Section }}: {{ .Title }} {{ .Resources }}'
+
+   Display the pages without tags using the 'untagged' template
+   of the project configuration:
+
+     hugo-utils list --template=untagged
+
+   Display how many posts each of the series of the site has:
+
+     hugo-utils \
+       --type=series \
+       '{{ .Name }}: {{ .Count }}'
 `,
 	ArgsUsage: "[format]",
-	Action:    listAction,
+	Action:    withProject(listAction),
 	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  "directory",
-			Usage: "path to the directory where contents exist (.md)",
-		},
+		directoryFlag,
+		contentTypesFlag,
 		cli.StringFlag{
 			Name:  "type",
-			Usage: "content type to list entries by (pages|tags|categories)",
+			Usage: "what to list: pages or the terms of a taxonomy (e.g., tags, categories, series)",
 			Value: "pages",
 		},
+		cli.StringFlag{
+			Name:  "taxonomies",
+			Usage: "plural names of the taxonomies, comma-separated (defaults to the site's or tags,categories)",
+		},
 		cli.StringFlag{
 			Name:  "sort",
-			Usage: "thing to sort by (title|date|lastmod)",
+			Usage: "thing to sort by (title|date|lastmod|publishdate|expirydate)",
 			Value: "lastmod",
 		},
 		cli.BoolFlag{
 			Name:  "draft",
 			Usage: "only show drafts",
 		},
+		cli.BoolFlag{
+			Name:  "future",
+			Usage: "also show pages scheduled to be published in the future",
+		},
+		cli.BoolFlag{
+			Name:  "expired",
+			Usage: "also show pages that expired",
+		},
+		cli.StringFlag{
+			Name:  "kind",
+			Usage: "only show pages of the given bundle kinds (single|leaf|branch, comma-separated)",
+		},
+		cli.StringFlag{
+			Name:  "template",
+			Usage: "name of a template of the project configuration to display the list with (unless a format is given)",
+		},
+		gitFlag,
+		workersFlag,
+		keepGoingFlag,
+		cacheDirFlag,
+		noCacheFlag,
 	},
 }
 
@@ -79,11 +200,49 @@ type renderState struct {
 	Pages []*hugo.Page
 }
 
-func showPagesList(c *cli.Context, pages []*hugo.Page) {
-	var (
-		format = c.String("format")
-		draft  = c.Bool("draft")
-	)
+type termRenderState struct {
+	*hugo.Term
+	Taxonomy *hugo.Taxonomy
+}
+
+// Body retrieves the body of the current page, loading it
+// from the page file only when a template makes use of it.
+func (r *renderState) Body() (body []byte, err error) {
+	err = r.Page.LoadBody()
+	if err != nil {
+		return
+	}
+
+	body = r.Page.Body
+	return
+}
+
+// listFormat retrieves the format that a list is displayed with:
+// the one given as argument or else the named template of the
+// project configuration that '--template' refers to (if any).
+func listFormat(c *cli.Context) (format string, err error) {
+	format = c.Args().First()
+	if format != "" || c.String("template") == "" {
+		return
+	}
+
+	format, ok := projectConfig(c).Templates[c.String("template")]
+	if !ok {
+		err = cli.NewExitError(fmt.Sprintf(
+			"unknown template %s", c.String("template")), 1)
+		return
+	}
+
+	return
+}
+
+func showPagesList(c *cli.Context, pages []*hugo.Page) (err error) {
+	var draft = c.Bool("draft")
+
+	format, err := listFormat(c)
+	if err != nil {
+		return
+	}
 
 	if format == "" {
 		w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
@@ -96,9 +255,15 @@ func showPagesList(c *cli.Context, pages []*hugo.Page) {
 
 			fmt.Fprintf(w, "%s\t%s\n", "title", page.Title)
 			fmt.Fprintf(w, "%s\t%v\n", "file", path.Base(page.Path))
+			fmt.Fprintf(w, "%s\t%v\n", "permalink", page.Permalink)
 			fmt.Fprintf(w, "%s\t%v\n", "slug", page.Slug)
-			fmt.Fprintf(w, "%s\t%v\n", "date", page.Date.Format("Jan 2, 2006"))
-			fmt.Fprintf(w, "%s\t%v\n", "last-mod", page.LastMod.Format("Jan 2, 2006"))
+			fmt.Fprintf(w, "%s\t%v\n", "date", formatDate(page.Dates.Date))
+			fmt.Fprintf(w, "%s\t%v\n", "last-mod", formatDate(page.Dates.LastMod))
+			if page.GitInfo != nil {
+				fmt.Fprintf(w, "%s\t%v\n", "last-author", page.GitInfo.AuthorName)
+			}
+			fmt.Fprintf(w, "%s\t%v\n", "publish-date", formatDate(page.Dates.PublishDate))
+			fmt.Fprintf(w, "%s\t%v\n", "expiry-date", formatDate(page.Dates.ExpiryDate))
 			fmt.Fprintf(w, "%s\t%v\n", "keywords", page.Keywords)
 			fmt.Fprintf(w, "%s\t%v\n", "tags", page.Tags)
 			fmt.Fprintf(w, "%s\t%v\n", "draft", page.Draft)
@@ -116,68 +281,187 @@ func showPagesList(c *cli.Context, pages []*hugo.Page) {
 	}
 
 	for _, page := range pages {
+		if draft && !page.Draft {
+			continue
+		}
+
 		err = t.Execute(os.Stdout, &renderState{page, pages})
 		if err != nil {
 			err = cli.NewExitError(err, 1)
+			return
 		}
 
 		fmt.Fprintln(os.Stdout, "")
 	}
+
+	return
 }
 
-func showTagsMap(c *cli.Context, pages []*hugo.Page) {
-	var tagsMapping = map[string][]*hugo.Page{}
+// formatDate formats a date for display, leaving dates
+// that aren't set blank.
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
 
-	for _, page := range pages {
-		if len(page.Tags) == 0 {
-			continue
+	return date.Format("Jan 2, 2006")
+}
+
+func showTaxonomy(c *cli.Context, taxonomy *hugo.Taxonomy) (err error) {
+	format, err := listFormat(c)
+	if err != nil {
+		return
+	}
+
+	if format == "" {
+		w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
+		for _, term := range taxonomy.Terms {
+			fmt.Fprintf(w, "%s (%d)\n", term.Name, term.Count())
+			for _, page := range term.Pages {
+				fmt.Fprintf(w, "\t%s\t(%s)\n", page.Title, path.Base(page.Path))
+			}
+			fmt.Fprintf(w, "\n")
 		}
+		w.Flush()
+		return
+	}
 
-		for _, tag := range page.Tags {
-			mapping, ok := tagsMapping[tag]
-			if !ok {
-				tagsMapping[tag] = []*hugo.Page{page}
-				continue
+	t, err := template.New("list-format").Parse(format)
+	if err != nil {
+		cli.ShowCommandHelp(c, "list")
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	for _, term := range taxonomy.Terms {
+		err = t.Execute(os.Stdout, &termRenderState{term, taxonomy})
+		if err != nil {
+			err = cli.NewExitError(err, 1)
+			return
+		}
+
+		fmt.Fprintln(os.Stdout, "")
+	}
+
+	return
+}
+
+// siteTaxonomies retrieves the plural names of the taxonomies
+// to list terms of: the ones given (comma-separated), the ones
+// of the site or, lacking both, hugo's defaults.
+func siteTaxonomies(config *site.Config, names string) (taxonomies []string) {
+	if names != "" {
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				taxonomies = append(taxonomies, strings.ToLower(name))
 			}
+		}
+
+		return
+	}
+
+	if config == nil || len(config.Taxonomies) == 0 {
+		taxonomies = hugo.DefaultTaxonomies
+		return
+	}
+
+	for _, plural := range config.Taxonomies {
+		taxonomies = append(taxonomies, plural)
+	}
+
+	sort.Strings(taxonomies)
+	return
+}
 
-			mapping = append(mapping, page)
-			tagsMapping[tag] = mapping
+// filterPagesByKind retains the pages whose bundle kind is
+// one of a comma-separated list of kinds.
+func filterPagesByKind(pages []*hugo.Page, kinds string) (filtered []*hugo.Page, err error) {
+	var (
+		wanted = map[hugo.BundleKind]bool{}
+		kind   hugo.BundleKind
+	)
+
+	for _, name := range strings.Split(kinds, ",") {
+		kind, err = hugo.ParseBundleKind(strings.TrimSpace(name))
+		if err != nil {
+			return
 		}
+
+		wanted[kind] = true
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
-	for tag, tagPages := range tagsMapping {
-		fmt.Fprintf(w, "%s\n", tag)
-		for _, page := range tagPages {
-			fmt.Fprintf(w, "\t%s\t(%s)\n", page.Title, path.Base(page.Path))
+	for _, page := range pages {
+		if wanted[page.Kind] {
+			filtered = append(filtered, page)
 		}
-		fmt.Fprintf(w, "\n")
-		fmt.Fprintf(w, "\n")
 	}
 
-	w.Flush()
 	return
+}
 
+// filterPagesByDates leaves out the pages that, at a given time,
+// are scheduled to be published in the future or expired, unless
+// `future` or `expired` (respectively) say they should be kept.
+func filterPagesByDates(pages []*hugo.Page, now time.Time, future, expired bool) (filtered []*hugo.Page) {
+	for _, page := range pages {
+		if !future && page.Dates.IsFuture(now) {
+			continue
+		}
+
+		if !expired && page.Dates.IsExpired(now) {
+			continue
+		}
+
+		filtered = append(filtered, page)
+	}
+
+	return
+}
+
+// showGatherErrors prints to 'stderr' a summary of the pages
+// that failed to be parsed.
+func showGatherErrors(gatherErr *hugo.GatherError, parsed int) {
+	for _, err := range gatherErr.Errors {
+		if parseErr, ok := errors.Cause(err).(*hugo.ParseError); ok {
+			fmt.Fprintln(os.Stderr, parseErr.Pretty())
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "%s\n\n", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d of %d page(s) failed to be parsed\n",
+		len(gatherErr.Errors), len(gatherErr.Errors)+parsed)
 }
 
 func listAction(c *cli.Context) (err error) {
 	var (
-		root     = c.String("directory")
 		listType = c.String("type")
 		sortBy   = c.String("sort")
+		kinds    = c.String("kind")
 	)
 
-	if root == "" {
-		cli.ShowCommandHelp(c, "list")
-		err = cli.NewExitError("a root path must be specified", 1)
+	config, pages, err := gatherSitePages(c, "list")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
 		return
 	}
 
-	pages, err := hugo.GatherPages(root)
-	if err != nil {
-		cli.ShowCommandHelp(c, "list")
-		err = cli.NewExitError(err, 1)
-		return
+	// pages that get filtered out still count as parsed.
+	parsed := len(pages)
+
+	// pages that failed to be parsed get reported at the end.
+	err = nil
+
+	pages = filterPagesByDates(pages, time.Now(), c.Bool("future"), c.Bool("expired"))
+
+	if kinds != "" {
+		pages, err = filterPagesByKind(pages, kinds)
+		if err != nil {
+			cli.ShowCommandHelp(c, "list")
+			err = cli.NewExitError(err, 1)
+			return
+		}
 	}
 
 	if sortBy != "" {
@@ -188,11 +472,19 @@ func listAction(c *cli.Context) (err error) {
 			})
 		case "date":
 			sort.Slice(pages, func(i, j int) bool {
-				return pages[i].Date.Before(pages[j].Date)
+				return pages[i].Dates.Date.Before(pages[j].Dates.Date)
 			})
 		case "lastmod":
 			sort.Slice(pages, func(i, j int) bool {
-				return pages[i].LastMod.Before(pages[j].LastMod)
+				return pages[i].Dates.LastMod.Before(pages[j].Dates.LastMod)
+			})
+		case "publishdate":
+			sort.Slice(pages, func(i, j int) bool {
+				return pages[i].Dates.PublishDate.Before(pages[j].Dates.PublishDate)
+			})
+		case "expirydate":
+			sort.Slice(pages, func(i, j int) bool {
+				return pages[i].Dates.ExpiryDate.Before(pages[j].Dates.ExpiryDate)
 			})
 		default:
 			cli.ShowCommandHelp(c, "list")
@@ -201,16 +493,41 @@ func listAction(c *cli.Context) (err error) {
 		}
 	}
 
-	switch listType {
-	case "tags":
-		showTagsMap(c, pages)
-	case "pages":
-		showPagesList(c, pages)
-	default:
-		cli.ShowCommandHelp(c, "list")
-		err = cli.NewExitError("unknown list type "+listType, 1)
+	if listType == "pages" {
+		err = showPagesList(c, pages)
+	} else {
+		taxonomies := siteTaxonomies(config, c.String("taxonomies"))
+		if !containsFold(taxonomies, listType) {
+			cli.ShowCommandHelp(c, "list")
+			err = cli.NewExitError(fmt.Sprintf(
+				"unknown list type %s (pages or one of the taxonomies: %s)",
+				listType, strings.Join(taxonomies, ", ")), 1)
+			return
+		}
+
+		err = showTaxonomy(c, hugo.NewTaxonomy(strings.ToLower(listType), pages))
+	}
+	if err != nil {
+		return
+	}
+
+	if partial {
+		showGatherErrors(gatherErr, parsed)
+		err = cli.NewExitError("", 1)
 		return
 	}
 
 	return
 }
+
+// containsFold indicates whether a list has a given string,
+// regardless of case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+
+	return false
+}
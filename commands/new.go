@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/site"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var New = cli.Command{
+	Name:  "new",
+	Usage: "creates new content from the site's archetypes.",
+	Description: `The 'new' command creates a content page (or page bundle) under
+   the content directory of the Hugo site that the working directory
+   belongs to, rendering it from the archetypes of the site just like
+   'hugo new' does.
+
+   The path of the new content is relative to the content directory
+   (e.g., 'posts/my-first-post.md'); '.md' is assumed when no extension
+   is given. The first directory of the path is the section of the new
+   content, which is also its type unless '--kind' says otherwise.
+
+   The archetype is looked up under the 'archetypes' directory of the
+   site ('archetypeDir') in order:
+   - 'archetypes/<type>/': a page bundle, whose files all get created
+     under a directory named after the new content;
+   - 'archetypes/<type>.<ext>';
+   - 'archetypes/default.<ext>';
+   - hugo's own default archetype.
+
+   Archetypes are Go templates that get:
+   - {{ .Name }}: the name of the new content (e.g., 'my-first-post');
+   - {{ .Date }}: the time of creation (RFC3339);
+   - {{ .Type }}: the type of the new content; and
+   - {{ .Section }}: its section.
+   The 'replace', 'title', 'lower', 'upper' and 'now' functions are
+   available too. Only the content files of bundles get rendered;
+   other files are copied as they are.
+
+   Nothing that already exists gets overwritten.
+
+EXAMPLES:
+
+   Create a post from 'archetypes/posts.md' (or the default one):
+
+     hugo-utils new posts/my-first-post.md
+
+   Create a trip bundle from 'archetypes/trip/' under 'content/trips':
+
+     hugo-utils new --kind trip trips/lisbon
+`,
+	ArgsUsage: "<section>/<name>",
+	Action:    withProject(newAction),
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "kind",
+			Usage: "type of the new content, naming its archetype (defaults to its section)",
+		},
+	},
+}
+
+func newAction(c *cli.Context) (err error) {
+	var (
+		target = filepath.ToSlash(c.Args().First())
+		kind   = c.String("kind")
+		ext    = path.Ext(target)
+	)
+
+	if target == "" {
+		cli.ShowCommandHelp(c, "new")
+		err = cli.NewExitError("the path of the new content must be specified", 1)
+		return
+	}
+
+	config, err := loadSite("")
+	if err != nil {
+		if err == site.ErrSiteNotFound {
+			err = cli.NewExitError(
+				"no hugo site found (the command must run within one)", 1)
+			return
+		}
+
+		err = exitError(err)
+		return
+	}
+
+	if ext == "" {
+		ext = ".md"
+		target += ext
+	}
+
+	data := site.ArchetypeData{
+		Name: strings.TrimSuffix(path.Base(target), ext),
+		Date: time.Now().Format(time.RFC3339),
+		Type: kind,
+	}
+
+	if dir := path.Dir(target); dir != "." {
+		data.Section = strings.Split(dir, "/")[0]
+	}
+
+	if data.Type == "" {
+		data.Type = data.Section
+	}
+
+	if data.Type == "" {
+		// hugo's type of the pages that belong
+		// to no section.
+		data.Type = "page"
+	}
+
+	var (
+		archetype = config.FindArchetype(data.Type, ext)
+		dest      = filepath.Join(
+			config.ContentPath(config.DefaultContentLanguage), filepath.FromSlash(target))
+	)
+
+	if archetype.Bundle {
+		dest = strings.TrimSuffix(dest, ext)
+	}
+
+	created, err := archetype.NewContent(dest, data)
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	for _, file := range created {
+		fmt.Println(file)
+	}
+
+	return
+}
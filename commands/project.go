@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cirocosta/hugo-utils/project"
+	"github.com/cirocosta/hugo-utils/site"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// projectMetadata is the key of the app metadata that holds the
+// project configuration loaded by `loadProject`.
+const projectMetadata = "project"
+
+// loadProject loads the project configuration (see `project.Load`)
+// of the site that a command is pointed at (by '--filepath' or
+// '--directory', or else the working directory), then gives the flags
+// of the command that weren't set in the command line the values
+// of their environment variables (`HUGO_UTILS_<COMMAND>_<FLAG>`,
+// e.g., `HUGO_UTILS_LIST_SORT`), or else the defaults of the
+// project file (`.hugo-utils.yaml` at the site root), or else the
+// ones of the user file (`~/.hugo-utils.yaml`).
+func loadProject(c *cli.Context) (err error) {
+	var (
+		command = c.Command.FullName()
+		flags   = map[string]cli.Flag{}
+	)
+
+	for _, flag := range c.Command.Flags {
+		if name := flagName(flag); name != "help" {
+			flags[name] = flag
+		}
+	}
+
+	for name, flag := range flags {
+		value, ok := os.LookupEnv(envVarName(command, name))
+		if !ok || c.IsSet(name) {
+			continue
+		}
+
+		var values = []string{value}
+		if _, isSlice := flag.(cli.StringSliceFlag); isSlice {
+			values = strings.Split(value, ",")
+		}
+
+		err = setFlag(c, name, values)
+		if err != nil {
+			err = cli.NewExitError(errors.Wrapf(err,
+				"invalid value of %s", envVarName(command, name)), 1)
+			return
+		}
+	}
+
+	start := defaultString(c.String("directory"), ".")
+	if path := c.String("filepath"); path != "" {
+		// commands that work on a single page (e.g., 'update')
+		// belong to the site of the page.
+		start = filepath.Dir(path)
+	}
+
+	root, err := site.FindRoot(start)
+	if err != nil && err != site.ErrSiteNotFound {
+		err = exitError(err)
+		return
+	}
+
+	config, err := project.Load(root)
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	c.App.Metadata[projectMetadata] = config
+
+	for name, value := range config.CommandDefaults(command) {
+		var values []string
+
+		if _, ok := flags[name]; !ok {
+			err = cli.NewExitError(fmt.Sprintf(
+				"unknown flag '%s' of command '%s' in %s",
+				name, command, strings.Join(config.Files, ", ")), 1)
+			return
+		}
+
+		if c.IsSet(name) {
+			continue
+		}
+
+		switch value := value.(type) {
+		case []interface{}:
+			for _, item := range value {
+				values = append(values, fmt.Sprint(item))
+			}
+
+			if _, isSlice := flags[name].(cli.StringSliceFlag); !isSlice {
+				// e.g., `kind: [single, leaf]`.
+				values = []string{strings.Join(values, ",")}
+			}
+		default:
+			values = []string{fmt.Sprint(value)}
+		}
+
+		err = setFlag(c, name, values)
+		if err != nil {
+			err = cli.NewExitError(errors.Wrapf(err,
+				"invalid default of flag '%s' of command '%s'", name, command), 1)
+			return
+		}
+	}
+
+	return
+}
+
+// withProject makes an action load the project configuration
+// (see `loadProject`) before it runs.
+func withProject(action func(*cli.Context) error) func(*cli.Context) error {
+	return func(c *cli.Context) (err error) {
+		err = loadProject(c)
+		if err != nil {
+			return
+		}
+
+		err = action(c)
+		return
+	}
+}
+
+// projectConfig retrieves the project configuration loaded
+// before the action ran (an empty one if none was).
+func projectConfig(c *cli.Context) *project.Config {
+	if config, ok := c.App.Metadata[projectMetadata].(*project.Config); ok {
+		return config
+	}
+
+	return &project.Config{}
+}
+
+// setFlag sets a flag to each of a set of values (more than one
+// only make sense for slice flags).
+func setFlag(c *cli.Context, name string, values []string) (err error) {
+	for _, value := range values {
+		err = c.Set(name, strings.TrimSpace(value))
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// flagName retrieves the (long) name of a flag.
+func flagName(flag cli.Flag) string {
+	return strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+}
+
+// envVarName retrieves the name of the environment variable that
+// sets a flag of a command (e.g., `HUGO_UTILS_BASELINE_CREATE_RULE`
+// for the `--rule` of `baseline create`).
+func envVarName(command, flag string) string {
+	name := "HUGO_UTILS_" + command + "_" + flag
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+}
+
+// defaultString retrieves a value, or a default if it's empty.
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
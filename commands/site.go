@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/site"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// The flags of the commands that go through the pages of a
+// site (see `gatherSitePages`).
+var (
+	directoryFlag = cli.StringFlag{
+		Name:  "directory",
+		Usage: "path to the directory where contents exist (defaults to the site's content directory)",
+	}
+
+	contentTypesFlag = cli.StringFlag{
+		Name:  "content-types",
+		Usage: "extensions of the content files, comma-separated (defaults to every extension hugo knows)",
+	}
+
+	workersFlag = cli.IntFlag{
+		Name:  "workers",
+		Usage: "number of pages to parse concurrently (defaults to the number of CPUs)",
+	}
+
+	keepGoingFlag = cli.BoolFlag{
+		Name:  "keep-going",
+		Usage: "go through the pages that could be parsed even if others failed (exits non-zero)",
+	}
+
+	noCacheFlag = cli.BoolFlag{
+		Name:  "no-cache",
+		Usage: "neither use nor update the cache of parsed pages",
+	}
+
+	outputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "format of the report of the findings: text, json, sarif, checkstyle or junit",
+		Value: "text",
+	}
+
+	gitFlag = cli.BoolFlag{
+		Name:  "git",
+		Usage: "take the last modification dates (and authors) of the pages from git history (implied by the site's enableGitInfo)",
+	}
+)
+
+// loadSite loads the configuration of the site that a content
+// directory belongs to, looking for it from the directory up (or
+// from the working directory if none is given).
+//
+// Not being in a site is fine as long as a directory is given:
+// in that case `config` is nil.
+func loadSite(directory string) (config *site.Config, err error) {
+	start := directory
+	if start == "" {
+		start = "."
+	}
+
+	root, err := site.FindRoot(start)
+	if err != nil {
+		if err == site.ErrSiteNotFound && directory != "" {
+			err = nil
+		}
+
+		return
+	}
+
+	config, err = site.Load(root)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to load configuration of site %s", root)
+		return
+	}
+
+	return
+}
+
+// siteContentDir retrieves the content directory (of the site or
+// of one of its languages) that a directory lives in, or the one
+// of the default language if no directory is given.
+func siteContentDir(config *site.Config, directory string) (contentDir string) {
+	if directory == "" {
+		contentDir = config.ContentPath(config.DefaultContentLanguage)
+		return
+	}
+
+	contentDir = config.ContentDirOf(directory)
+	return
+}
+
+// sitePermalinks retrieves the settings that the permalinks of
+// the pages under a content directory of a site are computed
+// from.
+func sitePermalinks(config *site.Config, contentDir string) (opts hugo.PermalinkOptions) {
+	opts = hugo.PermalinkOptions{
+		Patterns:                config.Permalinks,
+		DefaultLanguage:         config.DefaultContentLanguage,
+		DefaultLanguageInSubdir: config.DefaultContentLanguageInSubdir,
+	}
+
+	for code, lang := range config.Languages {
+		opts.Languages = append(opts.Languages, code)
+
+		if lang.ContentDir != "" && config.ContentPath(code) == contentDir {
+			opts.ContentLanguage = code
+		}
+	}
+	sort.Strings(opts.Languages)
+
+	return
+}
+
+// workingDirPath retrieves a path relative to the working
+// directory, or the path as it is if it can't be made so.
+func workingDirPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		return path
+	}
+
+	return rel
+}
+
+// siteCacheDir retrieves the directory where the parsed pages get
+// cached: the one given, or else `defaultCacheDir` under the root
+// of the site ("" when there's no site to keep it under).
+func siteCacheDir(c *cli.Context, config *site.Config) string {
+	if dir := c.String("cache-dir"); dir != "" {
+		return dir
+	}
+
+	if config == nil {
+		return ""
+	}
+
+	return filepath.Join(config.Root, defaultCacheDir)
+}
+
+// gatherSitePages gathers the front matter of the pages of the
+// site (or of the directory) that a command is pointed at, as
+// told by the flags of the command.
+//
+// With '--keep-going', the pages that could be parsed are returned
+// along with a `*hugo.GatherError` (which is left for the command
+// to report).
+func gatherSitePages(c *cli.Context, command string) (config *site.Config, pages []*hugo.Page, err error) {
+	var (
+		root  = c.String("directory")
+		cache *hugo.PageCache
+		opts  = hugo.GatherOptions{
+			KeepGoing:       c.Bool("keep-going"),
+			Workers:         c.Int("workers"),
+			FrontMatterOnly: true,
+		}
+	)
+
+	config, err = loadSite(root)
+	if err != nil {
+		if err == site.ErrSiteNotFound {
+			cli.ShowCommandHelp(c, command)
+			err = cli.NewExitError(
+				"a root path must be specified (no hugo site found)", 1)
+			return
+		}
+
+		err = exitError(err)
+		return
+	}
+
+	if config != nil {
+		opts.ContentDir = siteContentDir(config, root)
+		opts.IgnoreFiles = config.IgnoreFiles
+		opts.Permalinks = sitePermalinks(config, opts.ContentDir)
+		opts.Dates = hugo.DateSources(config.FrontMatter)
+
+		if root == "" {
+			root = opts.ContentDir
+		}
+	}
+
+	// keep the paths that get reported independent of where
+	// the site lives.
+	root = workingDirPath(root)
+
+	if c.Bool("git") || (config != nil && config.EnableGitInfo) {
+		opts.GitInfo, err = hugo.LoadGitInfo(root)
+		if err != nil {
+			err = exitError(err)
+			return
+		}
+	}
+
+	if c.String("content-types") != "" {
+		opts.ContentTypes, err = hugo.ParseContentTypes(c.String("content-types"))
+		if err != nil {
+			cli.ShowCommandHelp(c, command)
+			err = cli.NewExitError(err, 1)
+			return
+		}
+	}
+
+	if dir := siteCacheDir(c, config); dir != "" && !c.Bool("no-cache") {
+		cache, err = hugo.OpenPageCache(dir)
+		if err != nil {
+			// the pages can still be parsed, just not
+			// faster.
+			fmt.Fprintf(os.Stderr, "warning: %s (not using the cache)\n", err)
+			cache, err = nil, nil
+		} else {
+			opts.Cache = cache
+		}
+	}
+
+	pages, err = hugo.GatherPagesWithOptions(root, opts)
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		err = exitError(err)
+		return
+	}
+
+	if cache != nil {
+		if saveErr := cache.Save(); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", saveErr)
+		}
+	}
+
+	if partial {
+		err = gatherErr
+	}
+
+	return
+}
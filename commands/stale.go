@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Stale = cli.Command{
+	Name:  "stale",
+	Usage: "lists the pages that need attention for not being updated in a while.",
+	Description: `The 'stale' command goes through the content pages (just like
+   'list' does) and reports the ones that went longer than they
+   should without being modified, ranked by how long ago they went
+   stale (the ones that need attention the most come first).
+
+   How long ago a page was modified is told by its last modification
+   date ('lastmod', as the site's '[frontmatter]' configuration says),
+   which comes from the git history with '--git' (or 'enableGitInfo'
+   in the site configuration).
+
+   Pages can go without modifications for '--max-age' (e.g., '90d',
+   '2w', '6mo' or '1y'), unless '--threshold' says otherwise for the
+   pages of a section ('section:<section>=<age>') or for the pages
+   classified with a tag ('tag:<tag>=<age>'). Tags take precedence
+   over sections, and pages with more than one such tag get the
+   shortest of their thresholds.
+
+   Drafts, pages that list sections ('_index.md'), pages without
+   dates and pages marked as evergreen in their front matter
+   ('evergreen: true') are left out.
+
+   The list can be displayed as text, as JSON or as a Markdown
+   checklist ('--output'), e.g., to be pasted in an issue.
+
+EXAMPLES:
+
+   Display the pages of the site that the working directory belongs
+   to that weren't modified in the last year:
+
+     hugo-utils stale
+
+   Output:
+
+     overdue    last-mod       max-age    file                        title
+     120d       Sep 3, 2017    365d       content/posts/my-post.md    my post
+
+   Make Kubernetes posts go stale after 6 months, and notes after
+   two years, taking modification dates from git:
+
+     hugo-utils stale \
+       --git \
+       --threshold tag:kubernetes=6mo \
+       --threshold section:notes=2y
+
+   Create a review checklist:
+
+     hugo-utils stale --output markdown > review.md
+`,
+	Action: withProject(staleAction),
+	Flags: []cli.Flag{
+		directoryFlag,
+		contentTypesFlag,
+		cli.StringFlag{
+			Name:  "max-age",
+			Usage: "how long pages can go without modifications (e.g., 90d, 2w, 6mo or 1y)",
+			Value: "1y",
+		},
+		cli.StringSliceFlag{
+			Name:  "threshold",
+			Usage: "how long the pages of a section or tag can go without modifications, as 'section:<section>=<age>' or 'tag:<tag>=<age>'",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "format of the list: text, json or markdown",
+			Value: "text",
+		},
+		gitFlag,
+		workersFlag,
+		keepGoingFlag,
+		cacheDirFlag,
+		noCacheFlag,
+	},
+}
+
+// staleOptions retrieves the options of what makes pages stale
+// out of the flags of a command.
+func staleOptions(c *cli.Context) (opts hugo.StaleOptions, err error) {
+	opts = hugo.StaleOptions{
+		SectionMaxAges: map[string]time.Duration{},
+		TagMaxAges:     map[string]time.Duration{},
+	}
+
+	opts.MaxAge, err = hugo.ParseAge(c.String("max-age"))
+	if err != nil {
+		return
+	}
+
+	for _, threshold := range c.StringSlice("threshold") {
+		var (
+			maxAge time.Duration
+			parts  = strings.SplitN(threshold, "=", 2)
+			target = strings.SplitN(parts[0], ":", 2)
+		)
+
+		if len(parts) != 2 || len(target) != 2 {
+			err = errors.Errorf(
+				"malformed threshold %s (expected e.g. 'tag:<tag>=<age>')", threshold)
+			return
+		}
+
+		maxAge, err = hugo.ParseAge(parts[1])
+		if err != nil {
+			return
+		}
+
+		switch target[0] {
+		case "section":
+			opts.SectionMaxAges[target[1]] = maxAge
+		case "tag":
+			opts.TagMaxAges[target[1]] = maxAge
+		default:
+			err = errors.Errorf(
+				"unknown threshold target %s (expected 'section' or 'tag')", target[0])
+			return
+		}
+	}
+
+	return
+}
+
+// formatDays formats an amount of time as a number of days.
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// markdownLinkEscaper escapes the characters that would end the
+// text of a Markdown link early.
+var markdownLinkEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// markdownLink formats a Markdown link to a file, escaping its text
+// and percent-encoding its path (spaces and parentheses included).
+func markdownLink(text, path string) string {
+	target := (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+
+	return fmt.Sprintf("[%s](%s)", markdownLinkEscaper.Replace(text), target)
+}
+
+// staleEntry is how stale pages are described in JSON.
+type staleEntry struct {
+	Path        string    `json:"path"`
+	Title       string    `json:"title"`
+	Section     string    `json:"section"`
+	Permalink   string    `json:"permalink"`
+	LastMod     time.Time `json:"lastmod"`
+	AgeDays     int       `json:"ageDays"`
+	MaxAgeDays  int       `json:"maxAgeDays"`
+	OverdueDays int       `json:"overdueDays"`
+}
+
+func showStalePages(format string, stale []*hugo.StalePage) (err error) {
+	switch format {
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
+		fmt.Fprintf(w, "overdue\tlast-mod\tmax-age\tfile\ttitle\n")
+		for _, page := range stale {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				formatDays(page.Overdue()), formatDate(page.Page.Dates.LastMod),
+				formatDays(page.MaxAge), page.Page.Path, page.Page.Title)
+		}
+		w.Flush()
+	case "json":
+		var entries = []staleEntry{}
+
+		for _, page := range stale {
+			entries = append(entries, staleEntry{
+				Path:        page.Page.Path,
+				Title:       page.Page.Title,
+				Section:     page.Page.Section,
+				Permalink:   page.Page.Permalink,
+				LastMod:     page.Page.Dates.LastMod,
+				AgeDays:     int(page.Age.Hours() / 24),
+				MaxAgeDays:  int(page.MaxAge.Hours() / 24),
+				OverdueDays: int(page.Overdue().Hours() / 24),
+			})
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(entries)
+	case "markdown":
+		for _, page := range stale {
+			title := page.Page.Title
+			if title == "" {
+				title = page.Page.Path
+			}
+
+			fmt.Printf("- [ ] %s: last modified on %s, %d days overdue\n",
+				markdownLink(title, page.Page.Path), formatDate(page.Page.Dates.LastMod),
+				int(page.Overdue().Hours()/24))
+		}
+	default:
+		err = errors.Errorf("unknown format %s", format)
+	}
+
+	return
+}
+
+func staleAction(c *cli.Context) (err error) {
+	var format = c.String("output")
+
+	switch format {
+	case "text", "json", "markdown":
+	default:
+		cli.ShowCommandHelp(c, "stale")
+		err = cli.NewExitError(fmt.Sprintf("unknown format %s", format), 1)
+		return
+	}
+
+	opts, err := staleOptions(c)
+	if err != nil {
+		cli.ShowCommandHelp(c, "stale")
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	_, pages, err := gatherSitePages(c, "stale")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	parsed := len(pages)
+
+	err = showStalePages(format, hugo.FindStalePages(pages, opts, time.Now()))
+	if err != nil {
+		err = exitError(err)
+		return
+	}
+
+	if partial {
+		showGatherErrors(gatherErr, parsed)
+		err = cli.NewExitError("", 1)
+		return
+	}
+
+	return
+}
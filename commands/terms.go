@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/lint"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Terms = cli.Command{
+	Name:  "terms",
+	Usage: "checks that the terms of the taxonomies have metadata.",
+	Description: `The 'terms' command goes through the content pages (just like
+   'list' does), collects the terms of each taxonomy (see 'list
+   --type') and checks that every term has a page with its metadata
+   at '<taxonomy>/<term>/_index.md' (e.g., 'tags/kubernetes/_index.md',
+   with the term made into a path segment like Hugo does) that sets
+   a title, a description and an image.
+
+   Terms that lack any of those are reported, as well as pages of
+   terms that no page is classified with anymore. The command exits
+   with a non-zero code if there's any, unless the pages that lack
+   metadata are drafts (e.g., scaffolded ones that are yet to be
+   filled in), which are only reported.
+
+   With '--scaffold', the pages of the terms that have none get
+   created instead, as drafts titled after the terms. Hugo leaves
+   drafts out, so the terms keep their default pages until the
+   description and the image get filled in and 'draft' is removed.
+
+   With '--output' (json, sarif, checkstyle or junit), each problem
+   gets reported as a finding instead, just like 'lint' does: of the
+   'term-missing-page' rule for terms without pages (pointing at
+   where the page should be), of the 'term-missing-metadata' rule
+   for each entry that a page lacks (pointing at its line, if it's
+   there but empty) and of the 'term-unused' rule for the pages of
+   terms that aren't used. The findings of drafts are infos.
+
+EXAMPLES:
+
+   Check the terms of the taxonomies of the site that the working
+   directory belongs to:
+
+     hugo-utils terms
+
+   Output:
+
+     taxonomy    term            pages    problem                               file
+     tags        docker          0        unused                                content/tags/docker/_index.md
+     tags        kubernetes      3        missing description, image            content/tags/kubernetes/_index.md
+     tags        linux           2        missing description, image (draft)    content/tags/linux/_index.md
+     tags        Service Mesh    1        missing page                          content/tags/service-mesh/_index.md
+
+   Create the pages of the tags that have none:
+
+     hugo-utils terms --taxonomies tags --scaffold
+`,
+	Action: withProject(termsAction),
+	Flags: []cli.Flag{
+		directoryFlag,
+		contentTypesFlag,
+		cli.StringFlag{
+			Name:  "taxonomies",
+			Usage: "plural names of the taxonomies, comma-separated (defaults to the site's or tags,categories)",
+		},
+		cli.BoolFlag{
+			Name:  "scaffold",
+			Usage: "create the missing pages of the terms instead of reporting",
+		},
+		outputFlag,
+		workersFlag,
+		keepGoingFlag,
+		cacheDirFlag,
+		noCacheFlag,
+	},
+}
+
+// termProblem describes what the metadata of a term lacks.
+func termProblem(termPage *hugo.TermPage) string {
+	switch {
+	case termPage.Unused():
+		return "unused"
+	case termPage.Page == nil:
+		return "missing page"
+	case termPage.Draft():
+		return "missing " + strings.Join(termPage.Missing, ", ") + " (draft)"
+	default:
+		return "missing " + strings.Join(termPage.Missing, ", ")
+	}
+}
+
+// termPagePath retrieves the path to the page of a term, or to
+// where it should be created under a content directory.
+func termPagePath(termPage *hugo.TermPage, contentDir string) string {
+	if termPage.Page != nil {
+		return termPage.Page.Path
+	}
+
+	return filepath.Join(contentDir, termPage.Taxonomy, termPage.Dir(), "_index.md")
+}
+
+func showTermProblems(contentDir string, termPages []*hugo.TermPage) {
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 4, ' ', 0)
+	fmt.Fprintf(w, "taxonomy\tterm\tpages\tproblem\tfile\n")
+	for _, termPage := range termPages {
+		var count int
+		if !termPage.Unused() {
+			count = termPage.Term.Count()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			termPage.Taxonomy, termPage.Name, count,
+			termProblem(termPage), termPagePath(termPage, contentDir))
+	}
+	w.Flush()
+}
+
+// termFindings describes the problems of the terms as findings,
+// pointing at the pages of the terms (or at where they should be).
+func termFindings(contentDir string, termPages []*hugo.TermPage) (findings []lint.Finding) {
+	for _, termPage := range termPages {
+		var (
+			path = termPagePath(termPage, contentDir)
+			term = fmt.Sprintf("term %s of %s", termPage.Name, termPage.Taxonomy)
+		)
+
+		switch {
+		case termPage.Unused():
+			findings = append(findings, lint.Finding{
+				Rule:     "term-unused",
+				Severity: lint.SeverityError,
+				Path:     path,
+				Line:     1,
+				Message:  term + " isn't used by any page",
+			})
+		case termPage.Page == nil:
+			findings = append(findings, lint.Finding{
+				Rule:     "term-missing-page",
+				Severity: lint.SeverityError,
+				Path:     path,
+				Line:     1,
+				Message:  term + " has no page",
+			})
+		default:
+			severity := lint.SeverityError
+			if termPage.Draft() {
+				severity = lint.SeverityInfo
+			}
+
+			for _, key := range termPage.Missing {
+				finding := lint.Finding{
+					Rule:     "term-missing-metadata",
+					Severity: severity,
+					Path:     path,
+					Key:      key,
+					Line:     1,
+					Message:  fmt.Sprintf("%s has no %s", term, key),
+				}
+
+				if line := termPage.Page.KeyLine(key); line != 0 {
+					finding.Line = line
+				}
+
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return
+}
+
+func termsAction(c *cli.Context) (err error) {
+	var (
+		contentDir = c.String("directory")
+		problems   []*hugo.TermPage
+		drafts     int
+		created    int
+	)
+
+	format, err := lint.ParseReportFormat(c.String("output"))
+	if err != nil {
+		cli.ShowCommandHelp(c, "terms")
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	config, pages, err := gatherSitePages(c, "terms")
+	gatherErr, partial := err.(*hugo.GatherError)
+	if err != nil && !partial {
+		return
+	}
+
+	parsed := len(pages)
+
+	if config != nil {
+		contentDir = workingDirPath(siteContentDir(config, contentDir))
+	}
+
+	for _, taxonomy := range siteTaxonomies(config, c.String("taxonomies")) {
+		for _, termPage := range hugo.FindTermPages(hugo.NewTaxonomy(taxonomy, pages), pages) {
+			if termPage.Complete() {
+				continue
+			}
+
+			if c.Bool("scaffold") {
+				if termPage.Unused() || termPage.Page != nil {
+					continue
+				}
+
+				var path string
+
+				path, err = termPage.Scaffold(contentDir)
+				if err != nil {
+					err = exitError(err)
+					return
+				}
+
+				fmt.Printf("created %s\n", path)
+				created++
+				continue
+			}
+
+			if termPage.Draft() && !termPage.Unused() {
+				drafts++
+			}
+
+			problems = append(problems, termPage)
+		}
+	}
+
+	switch {
+	case c.Bool("scaffold"):
+		fmt.Printf("%d term page(s) created\n", created)
+	case format == lint.ReportFormatText:
+		showTermProblems(contentDir, problems)
+	default:
+		err = lint.WriteReport(os.Stdout, format, termFindings(contentDir, problems))
+		if err != nil {
+			err = exitError(err)
+			return
+		}
+	}
+
+	if partial {
+		showGatherErrors(gatherErr, parsed)
+		err = cli.NewExitError("", 1)
+		return
+	}
+
+	if len(problems) > drafts {
+		err = cli.NewExitError(fmt.Sprintf(
+			"%d term problem(s) found", len(problems)-drafts), 1)
+		return
+	}
+
+	return
+}
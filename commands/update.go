@@ -3,9 +3,12 @@ package commands
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
+	"time"
 
 	"github.com/cirocosta/hugo-utils/hugo"
 	"github.com/imdario/mergo"
+	"github.com/pkg/errors"
 	"gopkg.in/urfave/cli.v1"
 	"gopkg.in/yaml.v2"
 )
@@ -20,9 +23,30 @@ var Update = cli.Command{
    content page and applies to it the merge between the original
    frontmatter and the updated frontmatter.
 
+   Pages with TOML ('+++' delimiters) or JSON (a leading '{...}'
+   object) front matter are written back in the format they were
+   read in, even though the update is always given in 'yaml'.
+
+   Entries that 'hugo-utils' doesn't know about (e.g., 'aliases',
+   'weight' or custom parameters) are kept as they are, and can
+   also be set through the update.
+
    When no updated yaml is passed, the default frontmatter is
    applied (e.g., a post without 'tags' would now have 'tags: []').
 
+   Entries of the 'frontMatter' of the project configuration file
+   ('.hugo-utils.yaml' at the root of the site of the page, or
+   '~/.hugo-utils.yaml') that the page doesn't define get set to the
+   values there (e.g., 'author: me').
+
+   With '--lastmod-from-git', the 'lastmod' of the page is set to the
+   date of the last commit that touched the page file whenever the
+   page's is older (stale). Commits that only changed 'lastmod' don't
+   count, so that committing the result doesn't make the page stale
+   again. Pages whose 'lastmod' is up to date, as well as pages that
+   were never committed, are left untouched (unless an update is also
+   given).
+
 EXAMPLES:
 
    Update the contents of page1.md with the defaults of the FrontMatter
@@ -64,14 +88,42 @@ EXAMPLES:
        - tag2
        ---
        body
+
+   Update only the 'tags' of a page with YAML front matter, leaving
+   comments, the order and quoting of the other entries as they are
+   (no default values get added):
+
+     hugo-utils update --preserve-style --filepath ./page1.md 'tags: ["tag3"]'
+     cat ./page1.md
+       ---
+       # my first page
+       title: 'page1'
+       tags:
+         - tag3
+       ---
+       body
+
+   Bring the 'lastmod' of every post up to date with the git history,
+   touching only the entry that changes:
+
+     find ./content -name "*.md" | xargs -I {} \
+       hugo-utils update --preserve-style --lastmod-from-git --filepath={}
 `,
-	Action:    updateAction,
+	Action:    withProject(updateAction),
 	ArgsUsage: "[yaml]",
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  "filepath",
 			Usage: "path to the page file",
 		},
+		cli.BoolFlag{
+			Name:  "preserve-style",
+			Usage: "only rewrite the yaml entries that changed, keeping comments, order and quoting",
+		},
+		cli.BoolFlag{
+			Name:  "lastmod-from-git",
+			Usage: "set lastmod to the date of the last commit that touched the page (not counting the ones that only changed lastmod) when it's older",
+		},
 	},
 }
 
@@ -79,9 +131,10 @@ func updateAction(c *cli.Context) (err error) {
 	var (
 		pageFilepath = c.String("filepath")
 		yamlSrc      = c.Args().First()
-		updateFm     = &hugo.FrontMatter{}
+		preserve     = c.Bool("preserve-style")
+		fromGit      = c.Bool("lastmod-from-git")
+		updateFm     *hugo.FrontMatter
 		page         *hugo.Page
-		file         *os.File
 		tempFile     *os.File
 	)
 
@@ -91,33 +144,48 @@ func updateAction(c *cli.Context) (err error) {
 		return
 	}
 
-	file, err = os.Open(pageFilepath)
+	page, err = hugo.ParsePageFile(pageFilepath)
 	if err != nil {
-		err = cli.NewExitError(err, 1)
+		err = exitError(err)
 		return
 	}
-	defer file.Close()
 
-	page, err = hugo.ParsePage(file)
+	defaulted, err := setFrontMatterDefaults(page, projectConfig(c).FrontMatter)
 	if err != nil {
 		err = cli.NewExitError(err, 1)
 		return
 	}
 
 	if yamlSrc != "" {
-		err = yaml.Unmarshal([]byte(yamlSrc), updateFm)
+		updateFm, err = hugo.ParseFrontMatter(
+			hugo.FrontMatterFormatYAML, []byte(yamlSrc))
 		if err != nil {
 			err = cli.NewExitError(err, 1)
 			return
 		}
 
-		err = mergo.Merge(&page.FrontMatter, updateFm, mergo.WithOverride)
+		err = mergo.Merge(&page.FrontMatter, updateFm,
+			mergo.WithOverride, mergo.WithTransformers(timeTransformer{}))
 		if err != nil {
 			err = cli.NewExitError(err, 1)
 			return
 		}
 	}
 
+	if fromGit {
+		var stale bool
+
+		stale, err = setLastModFromGit(page)
+		if err != nil {
+			err = exitError(err)
+			return
+		}
+
+		if !stale && yamlSrc == "" && !defaulted {
+			return
+		}
+	}
+
 	tempFile, err = ioutil.TempFile("", "")
 	if err != nil {
 		err = cli.NewExitError(err, 1)
@@ -125,7 +193,11 @@ func updateAction(c *cli.Context) (err error) {
 	}
 	defer tempFile.Close()
 
-	err = page.Write(tempFile)
+	if preserve {
+		err = page.WritePreservingStyle(tempFile)
+	} else {
+		err = page.Write(tempFile)
+	}
 	if err != nil {
 		err = cli.NewExitError(err, 1)
 		return
@@ -145,3 +217,79 @@ func updateAction(c *cli.Context) (err error) {
 
 	return
 }
+
+// setLastModFromGit sets the lastmod of a page to the date of the
+// last commit that touched the page file (other than to only change
+// its lastmod) if the page's is older, indicating whether it was.
+//
+// Pages without history (e.g., new ones) are left as they are.
+func setLastModFromGit(page *hugo.Page) (stale bool, err error) {
+	file, err := hugo.LastContentChange(page.Path)
+	if err != nil || file == nil {
+		return
+	}
+
+	if !page.LastMod.Before(file.AuthorDate) {
+		return
+	}
+
+	page.LastMod = file.AuthorDate
+	stale = true
+	return
+}
+
+// setFrontMatterDefaults sets the entries of a page that its front
+// matter doesn't define to their default values, indicating whether
+// any was set.
+func setFrontMatterDefaults(page *hugo.Page, defaults map[string]interface{}) (defaulted bool, err error) {
+	var missing = map[string]interface{}{}
+
+	for key, value := range defaults {
+		if page.KeyLine(key) == 0 {
+			missing[key] = value
+		}
+	}
+
+	if len(missing) == 0 {
+		return
+	}
+
+	src, err := yaml.Marshal(missing)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to encode front matter defaults")
+		return
+	}
+
+	fm, err := hugo.ParseFrontMatter(hugo.FrontMatterFormatYAML, src)
+	if err != nil {
+		err = errors.Wrapf(err, "invalid front matter defaults")
+		return
+	}
+
+	err = mergo.Merge(&page.FrontMatter, fm,
+		mergo.WithOverride, mergo.WithTransformers(timeTransformer{}))
+	if err != nil {
+		return
+	}
+
+	defaulted = true
+	return
+}
+
+// timeTransformer makes mergo leave the dates of a page alone
+// when the update doesn't specify them (zero times).
+type timeTransformer struct{}
+
+func (t timeTransformer) Transformer(typ reflect.Type) func(dst, src reflect.Value) error {
+	if typ != reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+
+	return func(dst, src reflect.Value) (err error) {
+		if dst.CanSet() && !src.Interface().(time.Time).IsZero() {
+			dst.Set(src)
+		}
+
+		return
+	}
+}
@@ -0,0 +1,174 @@
+package hugo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BundleKind indicates how a page is organized in the
+// content directory, as described in Hugo's "page bundles".
+type BundleKind uint8
+
+const (
+	// BundleKindSingle is a regular page that isn't
+	// the index of a bundle.
+	BundleKindSingle BundleKind = iota
+
+	// BundleKindLeaf is the `index.md` of a directory
+	// that holds a single page and its resources.
+	BundleKindLeaf
+
+	// BundleKindBranch is the `_index.md` of a section
+	// (or of the home page).
+	BundleKindBranch
+)
+
+const (
+	leafBundleIndex   = "index"
+	branchBundleIndex = "_index"
+)
+
+// String returns the name of the kind.
+func (k BundleKind) String() string {
+	switch k {
+	case BundleKindSingle:
+		return "single"
+	case BundleKindLeaf:
+		return "leaf"
+	case BundleKindBranch:
+		return "branch"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseBundleKind retrieves the kind that has a given
+// name (`single`, `leaf` or `branch`).
+func ParseBundleKind(name string) (kind BundleKind, err error) {
+	for _, kind = range []BundleKind{
+		BundleKindSingle,
+		BundleKindLeaf,
+		BundleKindBranch,
+	} {
+		if kind.String() == name {
+			return
+		}
+	}
+
+	err = errors.Errorf("unknown bundle kind %s", name)
+	return
+}
+
+// bundleKindOf retrieves the kind of a page given
+// the name of its file.
+func bundleKindOf(path string) BundleKind {
+	switch strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) {
+	case leafBundleIndex:
+		return BundleKindLeaf
+	case branchBundleIndex:
+		return BundleKindBranch
+	default:
+		return BundleKindSingle
+	}
+}
+
+// sectionOf retrieves the section that a page belongs to: the
+// first directory under the (absolute) content directory that
+// contains it.
+//
+// Pages at the top of the content directory, as well as leaf
+// bundles living there, belong to no section.
+func sectionOf(contentDir, path string, kind BundleKind) (section string) {
+	dir := filepath.Dir(path)
+	if kind == BundleKindLeaf {
+		// the directory of a leaf bundle is the
+		// page itself, not a section.
+		dir = filepath.Dir(dir)
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+
+	rel, err := filepath.Rel(contentDir, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return
+	}
+
+	section = strings.Split(filepath.ToSlash(rel), "/")[0]
+	return
+}
+
+// findLeafBundleIndex looks for the content file that makes
+// a directory a leaf bundle, returning "" if there's none.
+func findLeafBundleIndex(dir string, filter contentFilter) (index string, err error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to read directory %s", dir)
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && filter.isContentFile(path) && bundleKindOf(path) == BundleKindLeaf {
+			index = path
+			return
+		}
+	}
+
+	return
+}
+
+// leafBundleResources retrieves every file that lives in a leaf
+// bundle (other than its index), including those in nested
+// directories and other content files.
+func leafBundleResources(dir, index string, filter contentFilter) (resources []string, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if info.IsDir() || path == index || filter.ignores(path) {
+			return nil
+		}
+
+		resources = append(resources, path)
+		return nil
+	})
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to gather resources of bundle %s", dir)
+		return
+	}
+
+	return
+}
+
+// branchBundleResources retrieves the files that live in a branch
+// bundle: the ones in the same directory as its index that aren't
+// content files (those are pages of the section).
+func branchBundleResources(dir string, filter contentFilter) (resources []string, err error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to gather resources of bundle %s", dir)
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || filter.ignores(path) || filter.isContentFile(path) {
+			continue
+		}
+
+		resources = append(resources, path)
+	}
+
+	return
+}
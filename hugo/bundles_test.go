@@ -0,0 +1,116 @@
+package hugo_test
+
+import (
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundles", func() {
+	Describe("DiscoverMarkdownPaths", func() {
+		It("leaves the content of leaf bundles out", func() {
+			paths, err := hugo.DiscoverMarkdownPaths("testdata/bundles")
+			Expect(err).To(Succeed())
+			Expect(paths).To(Equal([]string{
+				"testdata/bundles/_index.md",
+				"testdata/bundles/about/index.md",
+				"testdata/bundles/posts/_index.md",
+				"testdata/bundles/posts/first.md",
+				"testdata/bundles/posts/my-bundle/index.md",
+			}))
+		})
+	})
+
+	Describe("GatherPages", func() {
+		var pages = map[string]*hugo.Page{}
+
+		BeforeEach(func() {
+			gathered, err := hugo.GatherPages("testdata/bundles")
+			Expect(err).To(Succeed())
+
+			for _, page := range gathered {
+				pages[page.Title] = page
+			}
+		})
+
+		It("knows the kind of each page", func() {
+			Expect(pages["home"].Kind).To(Equal(hugo.BundleKindBranch))
+			Expect(pages["about"].Kind).To(Equal(hugo.BundleKindLeaf))
+			Expect(pages["posts"].Kind).To(Equal(hugo.BundleKindBranch))
+			Expect(pages["first"].Kind).To(Equal(hugo.BundleKindSingle))
+			Expect(pages["my bundle"].Kind).To(Equal(hugo.BundleKindLeaf))
+		})
+
+		It("knows the section of each page", func() {
+			Expect(pages["home"].Section).To(BeEmpty())
+			Expect(pages["about"].Section).To(BeEmpty())
+			Expect(pages["posts"].Section).To(Equal("posts"))
+			Expect(pages["first"].Section).To(Equal("posts"))
+			Expect(pages["my bundle"].Section).To(Equal("posts"))
+		})
+
+		It("gathers the resources of leaf bundles", func() {
+			Expect(pages["my bundle"].Resources).To(Equal([]string{
+				"testdata/bundles/posts/my-bundle/data/file.json",
+				"testdata/bundles/posts/my-bundle/image.png",
+				"testdata/bundles/posts/my-bundle/notes.md",
+			}))
+		})
+
+		It("gathers the resources of branch bundles", func() {
+			Expect(pages["posts"].Resources).To(Equal([]string{
+				"testdata/bundles/posts/banner.png",
+			}))
+			Expect(pages["home"].Resources).To(BeEmpty())
+		})
+
+		It("has no resources for single pages", func() {
+			Expect(pages["first"].Resources).To(BeEmpty())
+		})
+
+		Context("having a content directory above the root", func() {
+			It("makes sections relative to it", func() {
+				gathered, err := hugo.GatherPagesWithOptions("testdata/bundles/posts", hugo.GatherOptions{
+					ContentDir: "testdata/bundles",
+				})
+				Expect(err).To(Succeed())
+
+				for _, page := range gathered {
+					Expect(page.Section).To(Equal("posts"))
+				}
+			})
+		})
+	})
+
+	Describe("ParsePageFile", func() {
+		It("knows the kind of the page from its file name", func() {
+			page, err := hugo.ParsePageFile("testdata/bundles/posts/my-bundle/index.md")
+			Expect(err).To(Succeed())
+			Expect(page.Kind).To(Equal(hugo.BundleKindLeaf))
+
+			page, err = hugo.ParsePageFile("testdata/bundles/posts/my-bundle/notes.md")
+			Expect(err).To(Succeed())
+			Expect(page.Kind).To(Equal(hugo.BundleKindSingle))
+		})
+	})
+
+	Describe("ParseBundleKind", func() {
+		It("parses every kind", func() {
+			for _, kind := range []hugo.BundleKind{
+				hugo.BundleKindSingle,
+				hugo.BundleKindLeaf,
+				hugo.BundleKindBranch,
+			} {
+				parsed, err := hugo.ParseBundleKind(kind.String())
+				Expect(err).To(Succeed())
+				Expect(parsed).To(Equal(kind))
+			}
+		})
+
+		It("fails for unknown kinds", func() {
+			_, err := hugo.ParseBundleKind("page")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,346 @@
+package hugo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// pageCacheVersion gets bumped whenever what's kept for
+	// each page changes, making older caches be discarded.
+	pageCacheVersion = 1
+
+	// pageCacheFile is the name of the file that holds the
+	// cache under the cache directory.
+	pageCacheFile = "pages.gob"
+)
+
+func init() {
+	// types that the values of front matter parameters
+	// can have once decoded (besides the basic ones).
+	gob.Register(map[string]interface{}{})
+	gob.Register(map[interface{}]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// PageCache keeps the parsed front matter of pages on disk so
+// that gathering pages that didn't change since the last time
+// doesn't require parsing them again.
+//
+// A cached page is used as long as its file has the same size and
+// modification time as when it got cached, or, failing that, the
+// same content hash (e.g., after a fresh checkout that only changed
+// modification times).
+type PageCache struct {
+	dir     string
+	mu      sync.Mutex
+	entries map[string]pageCacheEntry
+	dirty   bool
+}
+
+// pageCacheEntry is what gets cached for each page file.
+type pageCacheEntry struct {
+	Size    int64
+	ModTime int64
+	Hash    []byte
+
+	Format         FrontMatterFormat
+	FrontMatter    []byte
+	RawFrontMatter []byte
+	Prefix         []byte
+	Opening        []byte
+	Closing        []byte
+	BodyOffset     int64
+}
+
+// pageCacheContents is what gets stored in the cache file.
+type pageCacheContents struct {
+	Version int
+	Entries map[string]pageCacheEntry
+}
+
+// OpenPageCache opens the cache that lives under a given
+// directory.
+//
+// A cache that doesn't exist yet (or that can't be decoded,
+// e.g., because it was written by another version) starts
+// empty; nothing gets written until `Save` is called.
+func OpenPageCache(dir string) (cache *PageCache, err error) {
+	if dir == "" {
+		err = errors.Errorf("a cache directory must be specified")
+		return
+	}
+
+	cache = &PageCache{
+		dir:     dir,
+		entries: map[string]pageCacheEntry{},
+	}
+
+	file, err := os.Open(filepath.Join(dir, pageCacheFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+
+		err = errors.Wrapf(err,
+			"failed to open page cache under %s", dir)
+		return
+	}
+	defer file.Close()
+
+	var contents pageCacheContents
+
+	decodeErr := gob.NewDecoder(file).Decode(&contents)
+	if decodeErr != nil || contents.Version != pageCacheVersion {
+		cache.dirty = true
+		return
+	}
+
+	if contents.Entries != nil {
+		cache.entries = contents.Entries
+	}
+
+	return
+}
+
+// Save writes the cache to disk (if anything changed), leaving
+// out the pages whose files don't exist anymore.
+func (c *PageCache) Save() (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return
+	}
+
+	for path := range c.entries {
+		_, statErr := os.Stat(path)
+		if os.IsNotExist(statErr) {
+			delete(c.entries, path)
+		}
+	}
+
+	err = os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create cache directory %s", c.dir)
+		return
+	}
+
+	file, err := ioutil.TempFile(c.dir, pageCacheFile)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create temporary cache file under %s", c.dir)
+		return
+	}
+	defer os.Remove(file.Name())
+
+	err = file.Chmod(0644)
+	if err != nil {
+		file.Close()
+		err = errors.Wrapf(err, "failed to set permissions of page cache")
+		return
+	}
+
+	err = gob.NewEncoder(file).Encode(&pageCacheContents{
+		Version: pageCacheVersion,
+		Entries: c.entries,
+	})
+	if err != nil {
+		file.Close()
+		err = errors.Wrapf(err, "failed to encode page cache")
+		return
+	}
+
+	err = file.Close()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write page cache")
+		return
+	}
+
+	err = os.Rename(file.Name(), filepath.Join(c.dir, pageCacheFile))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to replace page cache")
+		return
+	}
+
+	c.dirty = false
+	return
+}
+
+// CleanPageCache removes the cache that lives under a given
+// directory, as well as the directory if nothing else is there.
+func CleanPageCache(dir string) (err error) {
+	if dir == "" {
+		err = errors.Errorf("a cache directory must be specified")
+		return
+	}
+
+	err = os.Remove(filepath.Join(dir, pageCacheFile))
+	if err != nil && !os.IsNotExist(err) {
+		err = errors.Wrapf(err,
+			"failed to remove page cache under %s", dir)
+		return
+	}
+
+	// the directory is only removed when empty so that
+	// nothing other than the cache ever gets deleted.
+	os.Remove(dir)
+
+	err = nil
+	return
+}
+
+// parsePageFile parses a page file, reusing what got cached for
+// it if the file didn't change, or caching it otherwise.
+func (c *PageCache) parsePageFile(path string, frontMatterOnly bool) (page *Page, err error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to resolve absolute path of %s", path)
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to retrieve info from %s", path)
+		return
+	}
+
+	c.mu.Lock()
+	entry, cached := c.entries[key]
+	c.mu.Unlock()
+
+	if cached && entry.Size == info.Size() && entry.ModTime == info.ModTime().UnixNano() {
+		page, err = entry.page(path)
+		if err == nil {
+			if !frontMatterOnly {
+				err = page.LoadBody()
+			}
+
+			return
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to read file %s", path)
+		return
+	}
+
+	hash := sha256.Sum256(content)
+
+	if cached && bytes.Equal(entry.Hash, hash[:]) {
+		page, err = entry.page(path)
+		if err == nil {
+			entry.Size = info.Size()
+			entry.ModTime = info.ModTime().UnixNano()
+			c.store(key, entry)
+
+			if !frontMatterOnly {
+				page.Body = content[page.bodyOffset:]
+				page.lazy = false
+			}
+
+			return
+		}
+	}
+
+	page, err = parsePageReader(path, bytes.NewReader(content), parsePage)
+	if err != nil {
+		return
+	}
+
+	bodyOffset := len(content) - len(page.Body)
+
+	entry, err = newPageCacheEntry(page, bodyOffset)
+	if err == nil {
+		entry.Size = info.Size()
+		entry.ModTime = info.ModTime().UnixNano()
+		entry.Hash = hash[:]
+		c.store(key, entry)
+	}
+
+	// pages that can't be cached are still good to go.
+	err = nil
+
+	if frontMatterOnly {
+		page.unloadBody(bodyOffset)
+	}
+
+	return
+}
+
+// store records an entry for a given page file.
+func (c *PageCache) store(key string, entry pageCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = entry
+	c.dirty = true
+}
+
+// newPageCacheEntry creates the entry that represents a page
+// in the cache.
+func newPageCacheEntry(page *Page, bodyOffset int) (entry pageCacheEntry, err error) {
+	var frontMatter bytes.Buffer
+
+	err = gob.NewEncoder(&frontMatter).Encode(&page.FrontMatter)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to encode front matter of %s", page.Path)
+		return
+	}
+
+	entry = pageCacheEntry{
+		Format:         page.Format,
+		FrontMatter:    frontMatter.Bytes(),
+		RawFrontMatter: page.RawFrontMatter,
+		Prefix:         page.layout.prefix,
+		Opening:        page.layout.opening,
+		Closing:        page.layout.closing,
+		BodyOffset:     int64(bodyOffset),
+	}
+
+	return
+}
+
+// page recreates the page that an entry represents, leaving its
+// body to be loaded from the page file.
+func (e pageCacheEntry) page(path string) (page *Page, err error) {
+	page = &Page{
+		Path:           path,
+		Format:         e.Format,
+		RawFrontMatter: e.RawFrontMatter,
+		layout: pageLayout{
+			format:  e.Format,
+			prefix:  e.Prefix,
+			opening: e.Opening,
+			closing: e.Closing,
+		},
+		lazy:       true,
+		bodyOffset: e.BodyOffset,
+	}
+
+	err = gob.NewDecoder(bytes.NewReader(e.FrontMatter)).Decode(&page.FrontMatter)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to decode cached front matter of %s", path)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,145 @@
+package hugo_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageCache", func() {
+	var (
+		dir      string
+		cacheDir string
+		pagePath string
+		modTime  = time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	)
+
+	writePage := func(title string, modTime time.Time) {
+		Expect(ioutil.WriteFile(pagePath, []byte(`---
+title: '`+title+`'
+date: 2018-01-02
+aliases:
+  - /old
+weight: 3
+---
+body of `+title+`
+`), 0644)).To(Succeed())
+		Expect(os.Chtimes(pagePath, modTime, modTime)).To(Succeed())
+	}
+
+	gather := func(frontMatterOnly bool) (pages []*hugo.Page) {
+		cache, err := hugo.OpenPageCache(cacheDir)
+		Expect(err).To(Succeed())
+
+		pages, err = hugo.GatherPagesContext(context.Background(), filepath.Join(dir, "content"), hugo.GatherOptions{
+			FrontMatterOnly: frontMatterOnly,
+			Cache:           cache,
+		})
+		Expect(err).To(Succeed())
+		Expect(pages).To(HaveLen(1))
+
+		Expect(cache.Save()).To(Succeed())
+		return
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).To(Succeed())
+
+		Expect(os.Mkdir(filepath.Join(dir, "content"), 0755)).To(Succeed())
+
+		cacheDir = filepath.Join(dir, ".hugo-utils", "cache")
+		pagePath = filepath.Join(dir, "content", "page.md")
+		writePage("aaaa", modTime)
+
+		gather(false)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("gets saved to the cache directory", func() {
+		_, err := os.Stat(filepath.Join(cacheDir, "pages.gob"))
+		Expect(err).To(Succeed())
+	})
+
+	Context("with an unchanged page", func() {
+		It("gives the same page as parsing it", func() {
+			expected, err := hugo.ParsePageFile(pagePath)
+			Expect(err).To(Succeed())
+
+			page := gather(false)[0]
+			Expect(page.Path).To(Equal(pagePath))
+			Expect(page.Title).To(Equal(expected.Title))
+			Expect(page.Date.Equal(expected.Date)).To(BeTrue())
+			Expect(page.Params).To(Equal(expected.Params))
+			Expect(string(page.Body)).To(Equal(string(expected.Body)))
+
+			var actualContent, expectedContent bytes.Buffer
+			Expect(page.WritePreservingStyle(&actualContent)).To(Succeed())
+			Expect(expected.WritePreservingStyle(&expectedContent)).To(Succeed())
+			Expect(actualContent.String()).To(Equal(expectedContent.String()))
+		})
+
+		It("leaves the body out when parsing front matter only", func() {
+			page := gather(true)[0]
+			Expect(page.Body).To(BeNil())
+
+			Expect(page.LoadBody()).To(Succeed())
+			Expect(string(page.Body)).To(Equal("body of aaaa\n"))
+		})
+	})
+
+	Context("with a page that keeps its size and modification time", func() {
+		It("uses the cached front matter", func() {
+			writePage("bbbb", modTime)
+			Expect(gather(true)[0].Title).To(Equal("aaaa"))
+		})
+	})
+
+	Context("with a page whose modification time changed", func() {
+		It("uses the cached front matter if the content didn't change", func() {
+			writePage("aaaa", modTime.Add(time.Hour))
+			Expect(gather(false)[0].Title).To(Equal("aaaa"))
+		})
+
+		It("parses the page again if the content changed", func() {
+			writePage("bbbb", modTime.Add(time.Hour))
+			Expect(gather(false)[0].Title).To(Equal("bbbb"))
+			Expect(gather(false)[0].Title).To(Equal("bbbb"))
+		})
+	})
+
+	Context("having a corrupt cache file", func() {
+		It("starts from scratch", func() {
+			Expect(ioutil.WriteFile(
+				filepath.Join(cacheDir, "pages.gob"), []byte("garbage"), 0644)).To(Succeed())
+
+			Expect(gather(false)[0].Title).To(Equal("aaaa"))
+		})
+	})
+
+	Describe("CleanPageCache", func() {
+		It("removes the cache", func() {
+			Expect(hugo.CleanPageCache(cacheDir)).To(Succeed())
+
+			_, err := os.Stat(cacheDir)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("succeeds without a cache", func() {
+			Expect(hugo.CleanPageCache(filepath.Join(dir, "nothing"))).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,128 @@
+package hugo
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// URLClaim is a page getting published at a URL, either as
+// its permalink or as one of its aliases.
+type URLClaim struct {
+	Page *Page
+
+	// Alias indicates that the URL comes from the `aliases`
+	// of the page rather than from its permalink.
+	Alias bool
+}
+
+// URLCollision indicates that more than one page gets published
+// at the same URL, leaving all but one of them unreachable there.
+type URLCollision struct {
+	URL    string
+	Claims []URLClaim
+}
+
+// FindURLCollisions looks for the URLs that more than one page
+// claims, either through their permalinks or their aliases.
+//
+// Collisions are sorted by URL, and the claims of each of them
+// keep the order of the pages.
+func FindURLCollisions(pages []*Page) (collisions []*URLCollision) {
+	var claimed = map[string]*URLCollision{}
+
+	claim := func(url string, page *Page, alias bool) {
+		key := urlKey(url)
+
+		collision, ok := claimed[key]
+		if !ok {
+			collision = &URLCollision{URL: cleanURLPath(url)}
+			claimed[key] = collision
+		}
+
+		for _, claim := range collision.Claims {
+			if claim.Page == page {
+				// e.g., an alias pointing at the
+				// page's own permalink.
+				return
+			}
+		}
+
+		collision.Claims = append(collision.Claims, URLClaim{page, alias})
+	}
+
+	for _, page := range pages {
+		if page.Permalink != "" {
+			claim(page.Permalink, page, false)
+		}
+
+		for _, alias := range page.aliasURLs() {
+			claim(alias, page, true)
+		}
+	}
+
+	for _, collision := range claimed {
+		if len(collision.Claims) > 1 {
+			collisions = append(collisions, collision)
+		}
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].URL < collisions[j].URL
+	})
+
+	return
+}
+
+// Aliases retrieves the URLs that redirect to the page
+// (`aliases`), as written in the front matter.
+func (fm *FrontMatter) Aliases() (aliases []string) {
+	for key, value := range fm.Params {
+		if !strings.EqualFold(key, "aliases") {
+			continue
+		}
+
+		switch value := value.(type) {
+		case []interface{}:
+			for _, alias := range value {
+				aliases = append(aliases, fmt.Sprint(alias))
+			}
+		case []string:
+			aliases = value
+		case string:
+			aliases = []string{value}
+		}
+
+		return
+	}
+
+	return
+}
+
+// aliasURLs resolves the aliases of a page: as with Hugo, those
+// that aren't absolute are relative to the directory of the
+// permalink of the page.
+func (p *Page) aliasURLs() (urls []string) {
+	dir := path.Dir(strings.TrimSuffix(p.Permalink, "/"))
+
+	for _, alias := range p.Aliases() {
+		if !strings.HasPrefix(alias, "/") {
+			alias = path.Join(dir, alias)
+		}
+
+		urls = append(urls, alias)
+	}
+
+	return
+}
+
+// urlKey identifies the file that a URL gets published to, so
+// that `/post`, `/post/` and `/post/index.html` are the same.
+func urlKey(url string) string {
+	key := cleanURLPath(url)
+	key = strings.TrimSuffix(key, "/index.html")
+	key = strings.TrimSuffix(key, "/")
+
+	return key
+}
@@ -0,0 +1,63 @@
+package hugo_test
+
+import (
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Collisions", func() {
+	var pages []*hugo.Page
+
+	BeforeEach(func() {
+		var err error
+
+		pages, err = hugo.GatherPages("testdata/collisions")
+		Expect(err).To(Succeed())
+	})
+
+	claimsOf := func(collision *hugo.URLCollision) (claims []string) {
+		for _, claim := range collision.Claims {
+			kind := "permalink"
+			if claim.Alias {
+				kind = "alias"
+			}
+
+			claims = append(claims, claim.Page.Title+" "+kind)
+		}
+
+		return
+	}
+
+	Describe("Aliases", func() {
+		It("retrieves the aliases of the front matter", func() {
+			Expect(pages[5].Aliases()).To(Equal([]string{"/f/", "/posts/f/"}))
+			Expect(pages[6].Aliases()).To(Equal([]string{"/unique/"}))
+		})
+	})
+
+	Describe("FindURLCollisions", func() {
+		It("finds the URLs claimed by more than one page", func() {
+			collisions := hugo.FindURLCollisions(pages)
+			Expect(collisions).To(HaveLen(2))
+
+			Expect(collisions[0].URL).To(Equal("/old/"))
+			Expect(claimsOf(collisions[0])).To(Equal([]string{
+				"c alias",
+				"d permalink",
+			}))
+
+			Expect(collisions[1].URL).To(Equal("/posts/same/"))
+			Expect(claimsOf(collisions[1])).To(Equal([]string{
+				"a permalink",
+				"b permalink",
+				"e alias",
+			}))
+		})
+
+		It("finds nothing when every URL is unique", func() {
+			Expect(hugo.FindURLCollisions(pages[5:])).To(BeEmpty())
+		})
+	})
+})
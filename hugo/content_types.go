@@ -0,0 +1,134 @@
+package hugo
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MarkupType is the language that the body of a
+// content page is written in.
+type MarkupType string
+
+const (
+	MarkupMarkdown MarkupType = "markdown"
+	MarkupHTML     MarkupType = "html"
+	MarkupAsciiDoc MarkupType = "asciidoc"
+	MarkupOrg      MarkupType = "org"
+	MarkupRST      MarkupType = "rst"
+	MarkupPandoc   MarkupType = "pandoc"
+)
+
+// frontMatterOptional indicates whether pages written in the
+// markup can go without front matter, which Hugo renders as they
+// are (e.g., bare HTML files).
+func (m MarkupType) frontMatterOptional() bool {
+	return m != "" && m != MarkupMarkdown
+}
+
+// ContentTypes maps the extensions of the files that are
+// content pages (e.g., `.md`) to their markup type.
+type ContentTypes map[string]MarkupType
+
+// DefaultContentTypes holds every extension that Hugo
+// treats as content.
+var DefaultContentTypes = ContentTypes{
+	".md":       MarkupMarkdown,
+	".mdown":    MarkupMarkdown,
+	".markdown": MarkupMarkdown,
+	".html":     MarkupHTML,
+	".htm":      MarkupHTML,
+	".adoc":     MarkupAsciiDoc,
+	".asciidoc": MarkupAsciiDoc,
+	".ad":       MarkupAsciiDoc,
+	".org":      MarkupOrg,
+	".rst":      MarkupRST,
+	".pandoc":   MarkupPandoc,
+	".pdc":      MarkupPandoc,
+}
+
+// ParseContentTypes parses a comma-separated list of extensions
+// (e.g., `md,html,txt=markdown`) into content types.
+//
+// Extensions that Hugo knows about get their markup type by
+// default; any other needs it to be specified after an `=`.
+func ParseContentTypes(spec string) (types ContentTypes, err error) {
+	types = ContentTypes{}
+
+	for _, entry := range strings.Split(spec, ",") {
+		var (
+			parts  = strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			ext    = "." + strings.TrimPrefix(strings.TrimSpace(parts[0]), ".")
+			markup MarkupType
+		)
+
+		if ext == "." {
+			err = errors.Errorf("empty extension in %s", spec)
+			return
+		}
+
+		if len(parts) == 2 {
+			markup = MarkupType(strings.TrimSpace(parts[1]))
+		} else {
+			markup = DefaultContentTypes[ext]
+		}
+
+		if markup == "" {
+			err = errors.Errorf(
+				"extension %s needs a markup type (e.g., %s=markdown)", ext, ext[1:])
+			return
+		}
+
+		types[ext] = markup
+	}
+
+	return
+}
+
+// markupOf retrieves the markup type of a file, indicating
+// whether it's a content file at all.
+func (t ContentTypes) markupOf(path string) (markup MarkupType, ok bool) {
+	if t == nil {
+		t = DefaultContentTypes
+	}
+
+	markup, ok = t[strings.ToLower(filepath.Ext(path))]
+	return
+}
+
+// contentFilter tells which files of a content directory
+// are content pages and which ones are ignored altogether.
+type contentFilter struct {
+	types  ContentTypes
+	ignore []*regexp.Regexp
+}
+
+// ignores indicates whether a file is to be left out.
+func (f contentFilter) ignores(path string) bool {
+	for _, re := range f.ignore {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markupOf retrieves the markup type of a file, indicating
+// whether it's a content page at all.
+func (f contentFilter) markupOf(path string) (markup MarkupType, ok bool) {
+	if f.ignores(path) {
+		return
+	}
+
+	markup, ok = f.types.markupOf(path)
+	return
+}
+
+// isContentFile indicates whether a file is a content page.
+func (f contentFilter) isContentFile(path string) bool {
+	_, ok := f.markupOf(path)
+	return ok
+}
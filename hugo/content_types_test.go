@@ -0,0 +1,130 @@
+package hugo_test
+
+import (
+	"regexp"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContentTypes", func() {
+	Describe("DiscoverMarkdownPaths", func() {
+		It("finds every content format that hugo knows", func() {
+			paths, err := hugo.DiscoverMarkdownPaths("testdata/formats")
+			Expect(err).To(Succeed())
+			Expect(paths).To(Equal([]string{
+				"testdata/formats/page.adoc",
+				"testdata/formats/page.html",
+				"testdata/formats/page.markdown",
+				"testdata/formats/page.org",
+				"testdata/formats/page.pandoc",
+				"testdata/formats/page.rst",
+			}))
+		})
+	})
+
+	Describe("DiscoverContentPaths", func() {
+		It("finds only the content types specified", func() {
+			types, err := hugo.ParseContentTypes("html,txt=markdown")
+			Expect(err).To(Succeed())
+
+			paths, err := hugo.DiscoverContentPaths("testdata/formats", types)
+			Expect(err).To(Succeed())
+			Expect(paths).To(Equal([]string{
+				"testdata/formats/notes.txt",
+				"testdata/formats/page.html",
+			}))
+		})
+	})
+
+	Describe("GatherPages", func() {
+		It("parses the front matter and tags the markup of every page", func() {
+			pages, err := hugo.GatherPages("testdata/formats")
+			Expect(err).To(Succeed())
+
+			var titles = map[hugo.MarkupType]string{}
+			for _, page := range pages {
+				titles[page.Markup] = page.Title
+			}
+
+			Expect(titles).To(Equal(map[hugo.MarkupType]string{
+				hugo.MarkupAsciiDoc: "asciidoc page",
+				hugo.MarkupHTML:     "html page",
+				hugo.MarkupMarkdown: "markdown page",
+				hugo.MarkupOrg:      "org page",
+				hugo.MarkupPandoc:   "pandoc page",
+				hugo.MarkupRST:      "rst page",
+			}))
+		})
+	})
+
+	Describe("GatherPages w/ pages without front matter", func() {
+		It("takes pages of other markups than markdown as they are", func() {
+			pages, err := hugo.GatherPages("testdata/bare")
+			Expect(err).To(Succeed())
+			Expect(pages).To(HaveLen(2))
+
+			Expect(pages[0].Path).To(Equal("testdata/bare/about.html"))
+			Expect(pages[0].Markup).To(Equal(hugo.MarkupHTML))
+			Expect(pages[0].Title).To(BeEmpty())
+			Expect(string(pages[0].Body)).To(Equal("<h1>About</h1>\n<p>Rendered as it is.</p>\n"))
+
+			Expect(pages[1].Title).To(Equal("markdown page"))
+		})
+
+		It("loads their whole content as body later on", func() {
+			page, err := hugo.ParsePageFileFrontMatter("testdata/bare/about.html")
+			Expect(err).To(Succeed())
+			Expect(page.Body).To(BeEmpty())
+
+			Expect(page.LoadBody()).To(Succeed())
+			Expect(string(page.Body)).To(Equal("<h1>About</h1>\n<p>Rendered as it is.</p>\n"))
+		})
+	})
+
+	Describe("GatherPagesWithOptions", func() {
+		It("leaves out the files matching the ignored patterns", func() {
+			pages, err := hugo.GatherPagesWithOptions("testdata/formats", hugo.GatherOptions{
+				IgnoreFiles: []*regexp.Regexp{
+					regexp.MustCompile(`\.(org|rst)$`),
+					regexp.MustCompile(`/page\.html$`),
+				},
+			})
+			Expect(err).To(Succeed())
+
+			var paths []string
+			for _, page := range pages {
+				paths = append(paths, page.Path)
+			}
+
+			Expect(paths).To(Equal([]string{
+				"testdata/formats/page.adoc",
+				"testdata/formats/page.markdown",
+				"testdata/formats/page.pandoc",
+			}))
+		})
+	})
+
+	Describe("ParseContentTypes", func() {
+		It("knows the markup of hugo's extensions", func() {
+			types, err := hugo.ParseContentTypes(".md, adoc")
+			Expect(err).To(Succeed())
+			Expect(types).To(Equal(hugo.ContentTypes{
+				".md":   hugo.MarkupMarkdown,
+				".adoc": hugo.MarkupAsciiDoc,
+			}))
+		})
+
+		It("fails for unknown extensions without markup", func() {
+			_, err := hugo.ParseContentTypes("md,txt")
+			Expect(err).ToNot(Succeed())
+		})
+
+		It("fails for empty extensions", func() {
+			_, err := hugo.ParseContentTypes("md,,html")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
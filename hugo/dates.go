@@ -0,0 +1,217 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DateSources holds, for each of the dates of a page, the ordered
+// list of places that it's taken from: front matter keys (e.g.,
+// `publishdate`) or the special `:filename` (a date prefixing the
+// name of the file, e.g., `2018-01-02-my-post.md`), `:fileModTime`
+// (the modification time of the file), `:git` (the author date of
+// the last commit that touched the file, when git info is gathered)
+// and `:default` (Hugo's own list for the date).
+//
+// Dates that have no sources take them from `DefaultDateSources`.
+type DateSources struct {
+	Date        []string
+	LastMod     []string
+	PublishDate []string
+	ExpiryDate  []string
+}
+
+// DefaultDateSources are the places where Hugo takes the dates
+// of a page from unless configured otherwise.
+var DefaultDateSources = DateSources{
+	Date:        []string{"date", "publishdate", "lastmod"},
+	LastMod:     []string{":git", "lastmod", "date", "publishdate"},
+	PublishDate: []string{"publishdate", "date"},
+	ExpiryDate:  []string{"expirydate"},
+}
+
+// PageDates are the dates of a page as Hugo sees them, having
+// gone through the sources of each of them.
+type PageDates struct {
+	Date        time.Time
+	LastMod     time.Time
+	PublishDate time.Time
+	ExpiryDate  time.Time
+}
+
+// IsFuture indicates whether the page is scheduled to be
+// published after a given time.
+func (d PageDates) IsFuture(now time.Time) bool {
+	return d.PublishDate.After(now)
+}
+
+// IsExpired indicates whether the page stopped being
+// published before a given time.
+func (d PageDates) IsExpired(now time.Time) bool {
+	return !d.ExpiryDate.IsZero() && d.ExpiryDate.Before(now)
+}
+
+// dateKeyAliases are the other front matter keys that Hugo
+// takes some of the dates from.
+var dateKeyAliases = map[string][]string{
+	"publishdate": {"pubdate", "published"},
+	"lastmod":     {"modified"},
+	"expirydate":  {"unpublishdate"},
+}
+
+// filenameDate matches the names of the files that start
+// with a date (e.g., `2018-01-02-my-post`).
+var filenameDate = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-?(.*)$`)
+
+// resolve goes through the sources of each of the dates of a
+// page, taking the first one that's set, and indicates whether
+// any of them came from the name of the page file.
+//
+// As with Hugo, the last modification and publish dates that
+// aren't set default to the date of the page.
+func (s DateSources) resolve(page *Page) (dates PageDates, fromFilename bool) {
+	var (
+		modTime time.Time
+		statted bool
+		fields  = []struct {
+			date    *time.Time
+			sources []string
+		}{
+			{&dates.Date, expandDateSources(s.Date, DefaultDateSources.Date)},
+			{&dates.LastMod, expandDateSources(s.LastMod, DefaultDateSources.LastMod)},
+			{&dates.PublishDate, expandDateSources(s.PublishDate, DefaultDateSources.PublishDate)},
+			{&dates.ExpiryDate, expandDateSources(s.ExpiryDate, DefaultDateSources.ExpiryDate)},
+		}
+	)
+
+	for _, field := range fields {
+		for _, source := range field.sources {
+			var date time.Time
+
+			switch strings.ToLower(source) {
+			case ":filename":
+				date = dateOfFileName(page)
+				fromFilename = fromFilename || !date.IsZero()
+			case ":git":
+				if page.GitInfo != nil {
+					date = page.GitInfo.AuthorDate
+				}
+			case ":filemodtime":
+				if !statted {
+					if info, err := os.Stat(page.Path); err == nil {
+						modTime = info.ModTime()
+					}
+					statted = true
+				}
+
+				date = modTime
+			default:
+				date = page.dateEntry(source)
+			}
+
+			if !date.IsZero() {
+				*field.date = date
+				break
+			}
+		}
+	}
+
+	if dates.LastMod.IsZero() {
+		dates.LastMod = dates.Date
+	}
+
+	if dates.PublishDate.IsZero() {
+		dates.PublishDate = dates.Date
+	}
+
+	return
+}
+
+// expandDateSources replaces `:default` (or the lack of sources)
+// with the default sources of a date.
+func expandDateSources(sources, defaults []string) (expanded []string) {
+	if len(sources) == 0 {
+		return defaults
+	}
+
+	for _, source := range sources {
+		if source == ":default" {
+			expanded = append(expanded, defaults...)
+			continue
+		}
+
+		expanded = append(expanded, source)
+	}
+
+	return
+}
+
+// dateEntry retrieves the date that a front matter key (or
+// any of its aliases) holds, regardless of its case.
+func (fm *FrontMatter) dateEntry(key string) (date time.Time) {
+	key = strings.ToLower(key)
+
+	switch key {
+	case "date":
+		date = fm.Date
+	case "lastmod":
+		date = fm.LastMod
+	case "publishdate":
+		date = fm.PublishDate
+	case "expirydate":
+		date = fm.ExpiryDate
+	}
+
+	if !date.IsZero() {
+		return
+	}
+
+	for _, name := range append([]string{key}, dateKeyAliases[key]...) {
+		for param, value := range fm.Params {
+			if !strings.EqualFold(param, name) {
+				continue
+			}
+
+			switch value := value.(type) {
+			case time.Time:
+				date = value
+			case string:
+				date, _ = parseDate(value)
+			}
+
+			if !date.IsZero() {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// dateOfFileName retrieves the date that prefixes the name of
+// the file of a page (or of the directory of its bundle).
+func dateOfFileName(page *Page) (date time.Time) {
+	matches := filenameDate.FindStringSubmatch(pageFileName(page))
+	if matches == nil {
+		return
+	}
+
+	date, _ = time.Parse("2006-01-02", matches[1])
+	return
+}
+
+// pageFileName retrieves the name of the file of a page without
+// its extension or, for leaf bundles, the name of their directory.
+func pageFileName(page *Page) (name string) {
+	if page.Kind == BundleKindLeaf {
+		name = filepath.Base(filepath.Dir(page.Path))
+		return
+	}
+
+	name = filepath.Base(page.Path)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return
+}
@@ -0,0 +1,114 @@
+package hugo_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dates", func() {
+	var (
+		sources hugo.DateSources
+		pages   map[string]*hugo.Page
+	)
+
+	day := func(value string) time.Time {
+		date, err := time.Parse("2006-01-02", value)
+		Expect(err).To(Succeed())
+
+		return date
+	}
+
+	BeforeEach(func() {
+		sources = hugo.DateSources{}
+	})
+
+	JustBeforeEach(func() {
+		gathered, err := hugo.GatherPagesWithOptions("testdata/dates", hugo.GatherOptions{
+			Dates: sources,
+		})
+		Expect(err).To(Succeed())
+
+		pages = map[string]*hugo.Page{}
+		for _, page := range gathered {
+			pages[page.Title] = page
+		}
+	})
+
+	Context("with hugo's defaults", func() {
+		It("falls back to the other dates", func() {
+			dates := pages["scheduled"].Dates
+			Expect(dates.Date).To(Equal(day("2018-03-01")))
+			Expect(dates.LastMod).To(Equal(day("2018-03-01")))
+			Expect(dates.PublishDate).To(Equal(day("2999-01-01")))
+			Expect(dates.ExpiryDate.IsZero()).To(BeTrue())
+		})
+
+		It("takes dates from the keys that hugo treats as aliases", func() {
+			dates := pages["aliased"].Dates
+			Expect(dates.PublishDate).To(Equal(day("2017-05-06")))
+			Expect(dates.Date).To(Equal(day("2017-05-06")))
+			Expect(dates.LastMod).To(Equal(time.Date(2017, 6, 7, 10, 0, 0, 0, time.UTC)))
+		})
+
+		It("leaves the dates that aren't set as zero", func() {
+			Expect(pages["undated"].Dates).To(Equal(hugo.PageDates{}))
+			Expect(pages["hello world"].Dates).To(Equal(hugo.PageDates{}))
+		})
+
+		It("knows about future and expired pages", func() {
+			now := day("2020-01-01")
+
+			Expect(pages["scheduled"].Dates.IsFuture(now)).To(BeTrue())
+			Expect(pages["scheduled"].Dates.IsExpired(now)).To(BeFalse())
+			Expect(pages["expired"].Dates.IsFuture(now)).To(BeFalse())
+			Expect(pages["expired"].Dates.IsExpired(now)).To(BeTrue())
+			Expect(pages["undated"].Dates.IsFuture(now)).To(BeFalse())
+			Expect(pages["undated"].Dates.IsExpired(now)).To(BeFalse())
+		})
+	})
+
+	Context("with dates taken from file names", func() {
+		BeforeEach(func() {
+			sources.Date = []string{":filename", ":default"}
+		})
+
+		It("takes the date that prefixes the file name", func() {
+			Expect(pages["hello world"].Dates.Date).To(Equal(day("2018-01-02")))
+			Expect(pages["hello world"].Dates.LastMod).To(Equal(day("2018-01-02")))
+			Expect(pages["hello world"].Dates.PublishDate).To(Equal(day("2018-01-02")))
+			Expect(pages["scheduled"].Dates.Date).To(Equal(day("2018-03-01")))
+		})
+
+		It("takes what follows the date as the slug", func() {
+			Expect(pages["hello world"].Permalink).To(Equal("/hello-world/"))
+		})
+	})
+
+	Context("with dates taken from the modification time of the files", func() {
+		BeforeEach(func() {
+			sources.LastMod = []string{"lastmod", ":fileModTime"}
+		})
+
+		It("falls back to the modification time", func() {
+			Expect(pages["undated"].Dates.LastMod.IsZero()).To(BeFalse())
+			Expect(pages["aliased"].Dates.LastMod).To(Equal(time.Date(2017, 6, 7, 10, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("ParsePage", func() {
+		It("decodes known keys regardless of their case", func() {
+			page, err := hugo.ParsePage(bytes.NewReader([]byte(
+				"---\nTitle: 'title'\nlastMod: 2018-01-02T00:00:00Z\n---\n")))
+			Expect(err).To(Succeed())
+
+			Expect(page.Title).To(Equal("title"))
+			Expect(page.LastMod).To(Equal(day("2018-01-02")))
+			Expect(page.Params).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,294 @@
+package hugo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ParseErrorKind categorizes the failures that can happen
+// while parsing a page.
+type ParseErrorKind uint8
+
+const (
+	ParseErrorSyntax ParseErrorKind = iota
+	ParseErrorMissingDelimiter
+	ParseErrorUnterminated
+	ParseErrorTypeMismatch
+)
+
+// String returns a human readable description of the kind.
+func (k ParseErrorKind) String() string {
+	switch k {
+	case ParseErrorSyntax:
+		return "syntax error"
+	case ParseErrorMissingDelimiter:
+		return "missing delimiter"
+	case ParseErrorUnterminated:
+		return "unterminated front matter"
+	case ParseErrorTypeMismatch:
+		return "type mismatch"
+	default:
+		return "unknown error"
+	}
+}
+
+// ParseError describes why a page couldn't be parsed and
+// where in the page file the problem lies.
+type ParseError struct {
+	// Path is the path to the page file (if known).
+	Path string
+
+	// Line and Column point at the offending spot of
+	// the page (1-based, zero if unknown).
+	Line   int
+	Column int
+
+	// Snippet is the contents of the offending line.
+	Snippet string
+
+	// Kind categorizes the error.
+	Kind ParseErrorKind
+
+	// Err is the underlying error (if any).
+	Err error
+}
+
+// Error implements the error interface, formatting the error
+// as `path:line:column: kind: message`.
+func (e *ParseError) Error() string {
+	var location []string
+
+	if e.Path != "" {
+		location = append(location, e.Path)
+	}
+
+	if e.Line > 0 {
+		location = append(location, strconv.Itoa(e.Line))
+		if e.Column > 0 {
+			location = append(location, strconv.Itoa(e.Column))
+		}
+	}
+
+	msg := e.Kind.String()
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	if len(location) == 0 {
+		return msg
+	}
+
+	return strings.Join(location, ":") + ": " + msg
+}
+
+var (
+	errorLineRegexp     = regexp.MustCompile(`[Ll]ine (\d+)`)
+	errorLocationRegexp = regexp.MustCompile(`(Near )?[Ll]ine \d+( \(last key parsed '[^']*'\))?: `)
+)
+
+// newFrontMatterError creates a ParseError out of an error that
+// happened while decoding the front matter of a page, locating
+// the offending line in the page content.
+func newFrontMatterError(err error, content []byte, layout pageLayout, frontMatter []byte) (parseErr *ParseError) {
+	var (
+		line   int
+		column int
+		key    string
+		msg    = err.Error()
+	)
+
+	parseErr = &ParseError{Kind: ParseErrorSyntax}
+
+	switch e := err.(type) {
+	case *yaml.TypeError:
+		parseErr.Kind = ParseErrorTypeMismatch
+		if len(e.Errors) > 0 {
+			msg = e.Errors[0]
+			line = lineFromErrorMessage(msg)
+		}
+	case *json.SyntaxError:
+		line, column = lineAndColumn(frontMatter, int(e.Offset))
+	case *json.UnmarshalTypeError:
+		parseErr.Kind = ParseErrorTypeMismatch
+		key = e.Field
+	case *orgValueError:
+		parseErr.Kind = ParseErrorTypeMismatch
+		line = e.line
+	default:
+		line = lineFromErrorMessage(err.Error())
+		if layout.format == FrontMatterFormatTOML && line == 0 {
+			key = mismatchedTOMLKey(frontMatter)
+			if key != "" {
+				parseErr.Kind = ParseErrorTypeMismatch
+			}
+		}
+	}
+
+	// the locations that decoders give are relative to the
+	// front matter, so they're replaced by the ones in the page.
+	parseErr.Err = errors.New(errorLocationRegexp.ReplaceAllString(msg, ""))
+
+	if key != "" {
+		line, column = keyLineAndColumn(frontMatter, key)
+	}
+
+	if line == 0 {
+		return
+	}
+
+	if column == 0 {
+		column = valueColumn(nthLine(frontMatter, line))
+	}
+
+	parseErr.Line = lineOfOffset(content, len(layout.prefix)+len(layout.opening)) + line - 1
+	parseErr.Column = column
+	parseErr.Snippet = string(bytes.TrimRight(nthLine(content, parseErr.Line), "\r\n"))
+
+	return
+}
+
+// lineFromErrorMessage extracts the line number that decoders
+// embed in their error messages (e.g., `yaml: line 2: ...`).
+func lineFromErrorMessage(msg string) (line int) {
+	matches := errorLineRegexp.FindStringSubmatch(msg)
+	if matches == nil {
+		return
+	}
+
+	line, _ = strconv.Atoi(matches[1])
+	return
+}
+
+// mismatchedTOMLKey finds the known front matter key whose
+// TOML value can't be decoded into its field.
+func mismatchedTOMLKey(frontMatter []byte) (key string) {
+	var entries map[string]toml.Primitive
+
+	md, err := toml.Decode(string(frontMatter), &entries)
+	if err != nil {
+		return
+	}
+
+	fields := reflect.ValueOf(&FrontMatter{}).Elem()
+	for name, value := range entries {
+		if !isKnownFrontMatterKey(name) {
+			continue
+		}
+
+		for i := 0; i < fields.NumField(); i++ {
+			if !strings.EqualFold(strings.Split(fields.Type().Field(i).Tag.Get("toml"), ",")[0], name) {
+				continue
+			}
+
+			err = md.PrimitiveDecode(value, fields.Field(i).Addr().Interface())
+			if err != nil {
+				key = name
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// keyLineAndColumn finds the line (1-based) where a top-level
+// key is defined, as well as the column where its value starts.
+func keyLineAndColumn(content []byte, key string) (line, column int) {
+	keyRegexp := regexp.MustCompile(
+		`^\s*["']?(?i:` + regexp.QuoteMeta(key) + `)["']?\s*[:=]`)
+
+	for i, text := range splitLinesKeepingEnds(content) {
+		if keyRegexp.Match(text) {
+			line = i + 1
+			column = valueColumn(text)
+			return
+		}
+	}
+
+	return
+}
+
+// valueColumn retrieves the column (1-based) where the value of
+// a `key: value` line starts, or where the line's text starts if
+// no value can be found.
+func valueColumn(text []byte) (column int) {
+	trimmed := bytes.TrimRight(text, " \t\r\n")
+
+	if idx := bytes.IndexAny(trimmed, ":="); idx >= 0 {
+		rest := trimmed[idx+1:]
+		value := bytes.TrimLeft(rest, " \t")
+
+		if len(value) > 0 {
+			column = idx + 1 + len(rest) - len(value) + 1
+			return
+		}
+	}
+
+	column = len(trimmed) - len(bytes.TrimLeft(trimmed, " \t")) + 1
+	return
+}
+
+// lineAndColumn converts a byte offset into a line and
+// column (both 1-based).
+func lineAndColumn(content []byte, offset int) (line, column int) {
+	if offset > len(content) {
+		offset = len(content)
+	}
+
+	line = lineOfOffset(content, offset)
+	column = offset - (bytes.LastIndexByte(content[:offset], '\n') + 1)
+	if column == 0 {
+		column = 1
+	}
+
+	return
+}
+
+// lineOfOffset retrieves the line (1-based) that a byte
+// offset falls in.
+func lineOfOffset(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// nthLine retrieves a given line (1-based) from a content.
+func nthLine(content []byte, n int) []byte {
+	lines := splitLinesKeepingEnds(content)
+	if n < 1 || n > len(lines) {
+		return nil
+	}
+
+	return lines[n-1]
+}
+
+// Pretty renders the error followed by the snippet of the
+// page that caused it, with a marker under the offending
+// column, as in:
+//
+//	page.md:4:7: type mismatch: ...
+//	   4 | tags: foo
+//	     |       ^
+func (e *ParseError) Pretty() string {
+	res := e.Error() + "\n"
+	if e.Line == 0 {
+		return res
+	}
+
+	gutter := fmt.Sprintf("%4d | ", e.Line)
+	res += gutter + e.Snippet + "\n"
+
+	if e.Column > 0 {
+		res += strings.Repeat(" ", len(gutter)-2) + "| " +
+			strings.Repeat(" ", e.Column-1) + "^\n"
+	}
+
+	return res
+}
@@ -1,20 +1,370 @@
 package hugo
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
 	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
 // FrontMatter corresponds to the parsed front
 // matter of the page.
 type FrontMatter struct {
-	Title       string    `yaml:"title"`
-	Description string    `yaml:"description"`
-	Slug        string    `yaml:"slug"`
-	Image       string    `yaml:"image"`
-	Date        time.Time `yaml:"date"`
-	LastMod     time.Time `yaml:"lastmod"`
-	Tags        []string  `yaml:"tags"`
-	Categories  []string  `yaml:"categories"`
-	Keywords    []string  `yaml:"keywords"`
-	Draft       bool      `yaml:"draft"`
+	Title       string    `yaml:"title" toml:"title" json:"title"`
+	Description string    `yaml:"description" toml:"description" json:"description"`
+	Slug        string    `yaml:"slug" toml:"slug" json:"slug"`
+	Image       string    `yaml:"image" toml:"image" json:"image"`
+	Date        time.Time `yaml:"date" toml:"date" json:"date"`
+	LastMod     time.Time `yaml:"lastmod" toml:"lastmod" json:"lastmod"`
+	PublishDate time.Time `yaml:"publishDate,omitempty" toml:"publishDate,omitempty" json:"publishDate,omitempty"`
+	ExpiryDate  time.Time `yaml:"expiryDate,omitempty" toml:"expiryDate,omitempty" json:"expiryDate,omitempty"`
+	Tags        []string  `yaml:"tags" toml:"tags" json:"tags"`
+	Categories  []string  `yaml:"categories" toml:"categories" json:"categories"`
+	Keywords    []string  `yaml:"keywords" toml:"keywords" json:"keywords"`
+	Draft       bool      `yaml:"draft" toml:"draft" json:"draft"`
+
+	// Params holds every other entry of the front matter
+	// (e.g., `aliases`, `weight` or custom parameters),
+	// keyed by the name found in the page.
+	Params map[string]interface{} `yaml:"-" toml:"-" json:"-"`
+}
+
+// FrontMatterFormat indicates the language that the
+// front matter of a page is written in.
+type FrontMatterFormat uint8
+
+const (
+	FrontMatterFormatYAML FrontMatterFormat = iota
+	FrontMatterFormatTOML
+	FrontMatterFormatJSON
+	FrontMatterFormatOrg
+)
+
+var (
+	yamlFrontMatterDelim = []byte("---")
+	tomlFrontMatterDelim = []byte("+++")
+	jsonFrontMatterStart = []byte("{")
+)
+
+// String returns the name of the format.
+func (f FrontMatterFormat) String() string {
+	switch f {
+	case FrontMatterFormatYAML:
+		return "yaml"
+	case FrontMatterFormatTOML:
+		return "toml"
+	case FrontMatterFormatJSON:
+		return "json"
+	case FrontMatterFormatOrg:
+		return "org"
+	default:
+		return "unknown"
+	}
+}
+
+// delimiter returns the line that surrounds a front matter
+// written in the format.
+//
+// JSON and Org-mode front matter have no delimiters: the
+// object itself (or the `#+` lines) mark the boundaries of
+// the front matter.
+func (f FrontMatterFormat) delimiter() []byte {
+	switch f {
+	case FrontMatterFormatTOML:
+		return tomlFrontMatterDelim
+	case FrontMatterFormatJSON, FrontMatterFormatOrg:
+		return nil
+	default:
+		return yamlFrontMatterDelim
+	}
+}
+
+// frontMatterFormatFromDelimiter retrieves the format that
+// a given delimiter line starts.
+func frontMatterFormatFromDelimiter(line []byte) (format FrontMatterFormat, ok bool) {
+	switch {
+	case bytes.Equal(line, yamlFrontMatterDelim):
+		format, ok = FrontMatterFormatYAML, true
+	case bytes.Equal(line, tomlFrontMatterDelim):
+		format, ok = FrontMatterFormatTOML, true
+	}
+
+	return
+}
+
+// ParseFrontMatter parses front matter contents written
+// in a given format.
+func ParseFrontMatter(format FrontMatterFormat, data []byte) (fm *FrontMatter, err error) {
+	fm = &FrontMatter{}
+
+	err = fm.decode(format, data)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to parse %s front matter", format)
+		return
+	}
+
+	return
+}
+
+// decode parses the contents of a front matter written
+// in a given format.
+//
+// Entries that don't correspond to any of the known fields
+// are kept in `Params` so that they can be written back.
+func (fm *FrontMatter) decode(format FrontMatterFormat, data []byte) (err error) {
+	var entries = map[string]interface{}{}
+
+	err = unmarshalFrontMatter(format, data, fm)
+	if err != nil {
+		return
+	}
+
+	err = unmarshalFrontMatter(format, data, &entries)
+	if err != nil {
+		return
+	}
+
+	if format == FrontMatterFormatYAML {
+		err = fm.decodeYAMLKeysInOtherCase(entries)
+		if err != nil {
+			return
+		}
+	}
+
+	fm.Params = nil
+	for key, value := range entries {
+		if isKnownFrontMatterKey(key) {
+			continue
+		}
+
+		if fm.Params == nil {
+			fm.Params = map[string]interface{}{}
+		}
+
+		fm.Params[key] = value
+	}
+
+	return
+}
+
+// decodeYAMLKeysInOtherCase decodes the entries of a YAML front
+// matter that correspond to known fields but are written in some
+// other case (e.g., `lastMod`), as YAML keys only match the names
+// of the fields exactly while Hugo disregards their case.
+func (fm *FrontMatter) decodeYAMLKeysInOtherCase(entries map[string]interface{}) (err error) {
+	var renamed = map[string]interface{}{}
+
+	for key, value := range entries {
+		name, ok := knownFrontMatterKeys[strings.ToLower(key)]
+		if ok && name != key {
+			renamed[name] = value
+		}
+	}
+
+	if len(renamed) == 0 {
+		return
+	}
+
+	data, err := yaml.Marshal(renamed)
+	if err != nil {
+		return
+	}
+
+	err = yaml.Unmarshal(data, fm)
+	return
+}
+
+// encode writes the front matter to a writer using
+// the specified format.
+//
+// Known fields come first, in the order they're declared,
+// followed by the extra parameters sorted by key.
+func (fm *FrontMatter) encode(format FrontMatterFormat, w io.Writer) (err error) {
+	var (
+		known, extra []byte
+		fields       interface{} = fm
+	)
+
+	if format != FrontMatterFormatOrg {
+		fields = fm.withoutEmptyFields(format.String())
+	}
+
+	known, err = marshalFrontMatter(format, fields)
+	if err != nil {
+		return
+	}
+
+	if len(fm.Params) > 0 {
+		extra, err = marshalFrontMatter(format, fm.Params)
+		if err != nil {
+			return
+		}
+
+		if format == FrontMatterFormatJSON {
+			// splice both objects together so that there's
+			// a single `{ known..., extra... }` object.
+			known = append(bytes.TrimSuffix(known, []byte("\n}\n")), ',')
+			extra = bytes.TrimPrefix(extra, []byte("{"))
+		}
+
+		known = append(known, extra...)
+	}
+
+	_, err = w.Write(known)
+	return
+}
+
+// withoutEmptyFields retrieves a copy of the front matter that
+// leaves out the fields tagged `omitempty` (for a given format)
+// that hold nothing.
+//
+// Not every encoder considers zero dates as empty (e.g., TOML's
+// and JSON's), so this is done before encoding.
+func (fm *FrontMatter) withoutEmptyFields(tag string) interface{} {
+	var (
+		fields = reflect.ValueOf(fm).Elem()
+		t      = fields.Type()
+		kept   []reflect.StructField
+		values []reflect.Value
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		options := strings.Split(t.Field(i).Tag.Get(tag), ",")[1:]
+		if containsString(options, "omitempty") && isEmptyValue(fields.Field(i)) {
+			continue
+		}
+
+		kept = append(kept, t.Field(i))
+		values = append(values, fields.Field(i))
+	}
+
+	res := reflect.New(reflect.StructOf(kept)).Elem()
+	for i, value := range values {
+		res.Field(i).Set(value)
+	}
+
+	return res.Addr().Interface()
+}
+
+// containsString indicates whether a list has a given string.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmarshalFrontMatter decodes front matter contents written
+// in a given format into `v`.
+func unmarshalFrontMatter(format FrontMatterFormat, data []byte, v interface{}) (err error) {
+	switch format {
+	case FrontMatterFormatYAML:
+		err = yaml.Unmarshal(data, v)
+	case FrontMatterFormatTOML:
+		err = toml.Unmarshal(data, v)
+	case FrontMatterFormatJSON:
+		err = json.Unmarshal(data, v)
+	case FrontMatterFormatOrg:
+		err = unmarshalOrg(data, v)
+	default:
+		err = errors.Errorf("unknown front matter format %d", format)
+	}
+
+	return
+}
+
+// marshalFrontMatter encodes `v` in a given format.
+func marshalFrontMatter(format FrontMatterFormat, v interface{}) (data []byte, err error) {
+	switch format {
+	case FrontMatterFormatYAML:
+		data, err = yaml.Marshal(v)
+	case FrontMatterFormatTOML:
+		var buf bytes.Buffer
+		err = toml.NewEncoder(&buf).Encode(v)
+		data = buf.Bytes()
+	case FrontMatterFormatJSON:
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	case FrontMatterFormatOrg:
+		data, err = marshalOrg(v)
+	default:
+		err = errors.Errorf("unknown front matter format %d", format)
+	}
+
+	return
+}
+
+// knownFrontMatterKeys maps the lowercased names of the fields
+// that `FrontMatter` gives typed access to to their names.
+var knownFrontMatterKeys = func() map[string]string {
+	var (
+		keys = map[string]string{}
+		t    = reflect.TypeOf(FrontMatter{})
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		keys[strings.ToLower(name)] = name
+	}
+
+	return keys
+}()
+
+// isKnownFrontMatterKey indicates whether a front matter key
+// maps to one of the fields of `FrontMatter`.
+//
+// As with Hugo, keys are matched regardless of their case.
+func isKnownFrontMatterKey(key string) bool {
+	_, ok := knownFrontMatterKeys[strings.ToLower(key)]
+	return ok
+}
+
+// jsonObjectScanner keeps track of the nesting of a
+// JSON object that spans across several lines so that
+// the end of a JSON front matter can be found.
+type jsonObjectScanner struct {
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// feed consumes a line of the object, returning whether
+// the outermost object got closed in it.
+func (s *jsonObjectScanner) feed(line []byte) (closed bool) {
+	for _, c := range line {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString && c == '\\':
+			s.escaped = true
+		case c == '"':
+			s.inString = !s.inString
+		case s.inString:
+			continue
+		case c == '{':
+			s.depth++
+		case c == '}':
+			s.depth--
+			if s.depth == 0 {
+				closed = true
+				return
+			}
+		}
+	}
+
+	return
 }
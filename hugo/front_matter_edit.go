@@ -0,0 +1,385 @@
+package hugo
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// frontMatterEdit describes a change to a single
+// top-level entry of a front matter.
+type frontMatterEdit struct {
+	key    string
+	value  interface{}
+	remove bool
+}
+
+// diff computes the edits that need to be applied to
+// `original` so that it ends up with the values of `fm`.
+//
+// Fields that are empty in both are not considered as
+// changed, so defaults never get added.
+func (fm *FrontMatter) diff(original *FrontMatter) (edits []frontMatterEdit) {
+	var (
+		cur  = reflect.ValueOf(fm).Elem()
+		orig = reflect.ValueOf(original).Elem()
+		t    = cur.Type()
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if isEmptyValue(orig.Field(i)) && isEmptyValue(cur.Field(i)) {
+			continue
+		}
+
+		if reflect.DeepEqual(orig.Field(i).Interface(), cur.Field(i).Interface()) {
+			continue
+		}
+
+		edits = append(edits, frontMatterEdit{
+			key:   name,
+			value: cur.Field(i).Interface(),
+		})
+	}
+
+	keys := make([]string, 0, len(fm.Params)+len(original.Params))
+	for key := range fm.Params {
+		keys = append(keys, key)
+	}
+	for key := range original.Params {
+		if _, ok := fm.Params[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := fm.Params[key]
+		if !ok {
+			edits = append(edits, frontMatterEdit{key: key, remove: true})
+			continue
+		}
+
+		if reflect.DeepEqual(original.Params[key], value) {
+			continue
+		}
+
+		edits = append(edits, frontMatterEdit{key: key, value: value})
+	}
+
+	return
+}
+
+// editYAMLFrontMatter applies a set of edits to a YAML front
+// matter, rewriting only the lines of the entries that change.
+//
+// Comments, ordering and quoting of every other entry are left
+// byte-for-byte as they were. New entries are appended to the end.
+func editYAMLFrontMatter(src []byte, edits []frontMatterEdit) (res []byte, err error) {
+	type replacement struct {
+		start, end int
+		content    []byte
+	}
+
+	var (
+		doc          yamlv3.Node
+		mapping      *yamlv3.Node
+		lines        = splitLinesKeepingEnds(src)
+		replacements []replacement
+		appended     []byte
+		rendered     []byte
+	)
+
+	err = yamlv3.Unmarshal(src, &doc)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse yaml front matter")
+		return
+	}
+
+	if len(doc.Content) > 0 {
+		mapping = doc.Content[0]
+
+		if mapping.Kind != yamlv3.MappingNode {
+			err = errors.Errorf("front matter is not a yaml mapping")
+			return
+		}
+
+		if mapping.Style&yamlv3.FlowStyle != 0 {
+			err = errors.Errorf("flow-style front matter can't be edited in place")
+			return
+		}
+	}
+
+	for _, edit := range edits {
+		idx := yamlMappingKeyIndex(mapping, edit.key)
+		if idx < 0 {
+			if edit.remove {
+				continue
+			}
+
+			rendered, err = renderYAMLEntry(nil, nil, edit.key, edit.value)
+			if err != nil {
+				return
+			}
+
+			appended = append(appended, rendered...)
+			continue
+		}
+
+		start, end := yamlEntryLines(mapping, idx, lines)
+		rendered = nil
+
+		if edit.remove {
+			// the comment that introduces the entry goes
+			// along with it.
+			start = yamlHeadCommentStart(mapping.Content[idx], start, lines)
+		} else {
+			rendered, err = renderYAMLEntry(
+				mapping.Content[idx], mapping.Content[idx+1], edit.key, edit.value)
+			if err != nil {
+				return
+			}
+		}
+
+		replacements = append(replacements, replacement{start, end, rendered})
+	}
+
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start < replacements[j].start
+	})
+
+	var (
+		buf  bytes.Buffer
+		crlf = bytes.Contains(src, []byte("\r\n"))
+		line = 0
+	)
+
+	for _, r := range replacements {
+		buf.Write(bytes.Join(lines[line:r.start], nil))
+		buf.Write(withLineEndings(r.content, crlf))
+		line = r.end
+	}
+	buf.Write(bytes.Join(lines[line:], nil))
+
+	if len(appended) > 0 {
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.Write(withLineEndings([]byte("\n"), crlf))
+		}
+
+		buf.Write(withLineEndings(appended, crlf))
+	}
+
+	res = buf.Bytes()
+	return
+}
+
+// yamlMappingKeyIndex finds the position of the node that holds
+// a given key in a mapping, returning -1 if it's not there.
+//
+// An exact match is preferred, but keys are otherwise matched
+// regardless of their case, just like Hugo does.
+func yamlMappingKeyIndex(mapping *yamlv3.Node, key string) (idx int) {
+	idx = -1
+	if mapping == nil {
+		return
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		value := mapping.Content[i].Value
+
+		if value == key {
+			idx = i
+			return
+		}
+
+		if idx < 0 && strings.EqualFold(value, key) {
+			idx = i
+		}
+	}
+
+	return
+}
+
+// yamlEntryLines retrieves the range of lines ([start, end)) that
+// a top-level mapping entry spans.
+//
+// Blank lines and unindented comments that follow an entry are
+// not considered part of it, as they either separate entries or
+// belong to the next one.
+func yamlEntryLines(mapping *yamlv3.Node, idx int, lines [][]byte) (start, end int) {
+	start = mapping.Content[idx].Line - 1
+	end = len(lines)
+
+	if idx+2 < len(mapping.Content) {
+		end = mapping.Content[idx+2].Line - 1
+	}
+
+	for end > start+1 {
+		line := bytes.TrimRight(lines[end-1], " \t\r\n")
+		if len(line) != 0 && line[0] != '#' {
+			break
+		}
+
+		end--
+	}
+
+	return
+}
+
+// yamlHeadCommentStart retrieves the line where the head comment
+// of a key (the comment lines right above it) starts, or the line
+// of the key itself if it has none.
+func yamlHeadCommentStart(key *yamlv3.Node, start int, lines [][]byte) int {
+	var comments int
+
+	for _, line := range strings.Split(key.HeadComment, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			comments++
+		}
+	}
+
+	for ; comments > 0 && start > 0; comments-- {
+		line := bytes.TrimSpace(lines[start-1])
+		if len(line) == 0 || line[0] != '#' {
+			break
+		}
+
+		start--
+	}
+
+	return start
+}
+
+// renderYAMLEntry encodes a single `key: value` entry.
+//
+// When the entry already exists (`oldKey` and `oldValue`), the
+// original key, line comment and quoting style are kept.
+func renderYAMLEntry(oldKey, oldValue *yamlv3.Node, key string, value interface{}) (res []byte, err error) {
+	var (
+		keyNode   = yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}
+		valueNode yamlv3.Node
+		compact   bool
+	)
+
+	err = valueNode.Encode(value)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to encode value of %s", key)
+		return
+	}
+
+	if oldKey != nil {
+		keyNode = *oldKey
+		keyNode.HeadComment, keyNode.FootComment = "", ""
+
+		inheritYAMLStyle(&valueNode, oldValue)
+		valueNode.LineComment = oldValue.LineComment
+
+		compact = oldValue.Kind == yamlv3.SequenceNode &&
+			oldValue.Style&yamlv3.FlowStyle == 0 &&
+			len(oldValue.Content) > 0 &&
+			oldValue.Content[0].Column == oldKey.Column+len("- ")
+	}
+
+	var (
+		buf     bytes.Buffer
+		encoder = yamlv3.NewEncoder(&buf)
+	)
+
+	encoder.SetIndent(2)
+	err = encoder.Encode(&yamlv3.Node{
+		Kind:    yamlv3.MappingNode,
+		Content: []*yamlv3.Node{&keyNode, &valueNode},
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "failed to render entry %s", key)
+		return
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to render entry %s", key)
+		return
+	}
+
+	res = buf.Bytes()
+	if compact {
+		// keep sequences that were written with their items at
+		// the same column as their key (`- item`) that way.
+		lines := splitLinesKeepingEnds(res)
+		for i := 1; i < len(lines); i++ {
+			lines[i] = bytes.TrimPrefix(lines[i], []byte("  "))
+		}
+		res = bytes.Join(lines, nil)
+	}
+
+	return
+}
+
+// inheritYAMLStyle makes a freshly encoded node look like the
+// node it replaces (quoting of strings, flow sequences, etc).
+func inheritYAMLStyle(node, old *yamlv3.Node) {
+	if node.Kind != old.Kind {
+		return
+	}
+
+	switch node.Kind {
+	case yamlv3.ScalarNode:
+		if node.Tag == "!!str" && old.Tag == "!!str" {
+			node.Style = old.Style
+		}
+	case yamlv3.SequenceNode, yamlv3.MappingNode:
+		node.Style |= old.Style & yamlv3.FlowStyle
+
+		if node.Kind == yamlv3.SequenceNode && len(old.Content) > 0 {
+			for _, item := range node.Content {
+				inheritYAMLStyle(item, old.Content[0])
+			}
+		}
+	}
+}
+
+// isEmptyValue indicates whether a field holds nothing
+// that would be worth writing (zero values, empty slices
+// and maps, and zero times).
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.IsZero()
+	}
+
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// splitLinesKeepingEnds splits a content in lines, keeping the
+// line terminators in each of them.
+func splitLinesKeepingEnds(content []byte) (lines [][]byte) {
+	lines = bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return
+}
+
+// withLineEndings converts `\n` line endings to `\r\n` when
+// the content being edited uses those.
+func withLineEndings(content []byte, crlf bool) []byte {
+	if !crlf {
+		return content
+	}
+
+	return bytes.Replace(content, []byte("\n"), []byte("\r\n"), -1)
+}
@@ -0,0 +1,248 @@
+package hugo
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GitInfo holds what the history of a git repository tells
+// about the files in it.
+type GitInfo struct {
+	// files holds the last commit of each file, keyed by the
+	// absolute path of the file (with symlinks resolved).
+	files map[string]*GitFileInfo
+}
+
+// GitFileInfo describes the last commit that touched a file.
+type GitFileInfo struct {
+	Hash        string
+	Subject     string
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  time.Time
+}
+
+const (
+	gitRecordSeparator = "\x1e"
+	gitFieldSeparator  = "\x1f"
+)
+
+// gitLogFormat makes each commit of `git log` start with the
+// record separator, followed by its fields and then the files
+// that it touched (one per line).
+var gitLogFormat = "--format=" + gitRecordSeparator + strings.Join([]string{
+	"%H", "%s", "%aN", "%aE", "%aI",
+}, gitFieldSeparator)
+
+// LoadGitInfo goes through the history of the git repository that
+// a path (a directory or a single file) belongs to, gathering the
+// last commit that touched each of the files under the path for more
+// than bringing its `lastmod` entry up to date.
+//
+// Leaving out the commits that only changed `lastmod` makes setting
+// it from the history stable: committing the new `lastmod` doesn't
+// make it outdated again.
+//
+// Files that have no such commits (e.g., untracked ones) have no info.
+func LoadGitInfo(path string) (info *GitInfo, err error) {
+	var (
+		dir      = path
+		pathspec = "."
+	)
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to stat %s", path)
+		return
+	}
+
+	if !stat.IsDir() {
+		dir, pathspec = filepath.Dir(path), filepath.Base(path)
+	}
+
+	out, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return
+	}
+
+	top, err := filepath.EvalSymlinks(strings.TrimSpace(string(out)))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to resolve git repository of %s", path)
+		return
+	}
+
+	out, err = git(dir, "-c", "core.quotepath=off",
+		"log", "--no-merges", "--no-renames", "-p", "-U0", gitLogFormat, "--", pathspec)
+	if err != nil {
+		return
+	}
+
+	info = &GitInfo{files: map[string]*GitFileInfo{}}
+
+	for _, record := range strings.Split(string(out), gitRecordSeparator) {
+		lines := strings.Split(record, "\n")
+
+		var file *GitFileInfo
+
+		file, err = parseGitCommit(lines[0])
+		if err != nil {
+			return
+		}
+
+		if file == nil {
+			continue
+		}
+
+		for _, name := range contentChanges(lines[1:]) {
+			// commits come from the most recent to the
+			// oldest: the first one of a file is its last.
+			key := filepath.Join(top, filepath.FromSlash(name))
+			if _, ok := info.files[key]; !ok {
+				info.files[key] = file
+			}
+		}
+	}
+
+	return
+}
+
+// lastModChange matches the lines of a diff that add or remove a
+// `lastmod` entry, in any front matter format.
+var lastModChange = regexp.MustCompile(`(?i)^[+-]\s*("lastmod"\s*:|lastmod\s*[:=]|#\+lastmod:)`)
+
+// contentChanges retrieves the files that the diff of a commit (as
+// `git log -p -U0 --no-renames` displays it) changed for more than
+// their `lastmod` entry.
+func contentChanges(lines []string) (names []string) {
+	var (
+		name    string
+		inHunk  bool
+		changes int
+		others  bool
+	)
+
+	flush := func() {
+		// changes without lines (e.g., of modes or of binary
+		// files) count as changes of the content too.
+		if name != "" && (others || changes == 0) {
+			names = append(names, name)
+		}
+	}
+
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			name, inHunk, changes, others = gitDiffName(line), false, 0, false
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")):
+			changes++
+			if !lastModChange.MatchString(line) {
+				others = true
+			}
+		}
+	}
+
+	flush()
+	return
+}
+
+// gitDiffName retrieves the name of the file of a `diff --git a/<name>
+// b/<name>` line, whose two names are the same without renames.
+func gitDiffName(line string) string {
+	names := strings.TrimPrefix(line, "diff --git ")
+	name := names[:(len(names)-1)/2]
+
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+	}
+
+	return strings.TrimPrefix(name, "a/")
+}
+
+// LastContentChange retrieves the last commit that touched a file
+// for more than bringing its `lastmod` entry up to date (see
+// `LoadGitInfo`), or nil if the file has no such commit (e.g., it's
+// untracked).
+func LastContentChange(path string) (file *GitFileInfo, err error) {
+	info, err := LoadGitInfo(path)
+	if err != nil {
+		return
+	}
+
+	file = info.File(path)
+	return
+}
+
+// parseGitCommit parses the line that starts a commit in the output
+// of `git log` (see `gitLogFormat`), retrieving nil if it's not one.
+func parseGitCommit(line string) (file *GitFileInfo, err error) {
+	fields := strings.Split(line, gitFieldSeparator)
+	if len(fields) != 5 {
+		return
+	}
+
+	date, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to parse date of commit %s", fields[0])
+		return
+	}
+
+	file = &GitFileInfo{
+		Hash:        fields[0],
+		Subject:     fields[1],
+		AuthorName:  fields[2],
+		AuthorEmail: fields[3],
+		AuthorDate:  date,
+	}
+
+	return
+}
+
+// File retrieves what the last commit that touched a file tells
+// about it, or nil if the file has no history.
+func (g *GitInfo) File(path string) *GitFileInfo {
+	if g == nil {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+
+	return g.files[abs]
+}
+
+// git runs a git command in a directory, retrieving its output.
+func git(dir string, args ...string) (out []byte, err error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+
+	out, err = cmd.Output()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to run 'git %s' in %s: %s",
+			strings.Join(args, " "), dir, strings.TrimSpace(stderr.String()))
+		return
+	}
+
+	return
+}
@@ -0,0 +1,164 @@
+package hugo_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Git", func() {
+	var repo string
+
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=author", "GIT_AUTHOR_EMAIL=author@example.com",
+			"GIT_COMMITTER_NAME=committer", "GIT_COMMITTER_EMAIL=committer@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+
+		out, err := cmd.CombinedOutput()
+		Expect(err).To(Succeed(), string(out))
+	}
+
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		repo, err = ioutil.TempDir("", "hugo-utils-git")
+		Expect(err).To(Succeed())
+
+		git("2018-01-02T10:00:00Z", "init", "-q")
+
+		write("content/first.md", "---\ntitle: first\nlastmod: 2017-01-01T00:00:00Z\n---\n")
+		write("content/second.md", "---\ntitle: second\nlastmod: 2017-01-01T00:00:00Z\n---\n")
+		git("2018-01-02T10:00:00Z", "add", ".")
+		git("2018-01-02T10:00:00Z", "commit", "-q", "-m", "add posts")
+
+		write("content/second.md", "---\ntitle: second\nlastmod: 2017-01-01T00:00:00Z\n---\nbody\n")
+		git("2018-02-03T10:00:00Z", "commit", "-q", "-a", "-m", "fix second")
+
+		write("content/untracked.md", "---\ntitle: untracked\nlastmod: 2017-01-01T00:00:00Z\n---\n")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(repo)
+	})
+
+	Describe("LoadGitInfo", func() {
+		It("retrieves the last commit of each file", func() {
+			info, err := hugo.LoadGitInfo(filepath.Join(repo, "content"))
+			Expect(err).To(Succeed())
+
+			first := info.File(filepath.Join(repo, "content", "first.md"))
+			Expect(first).NotTo(BeNil())
+			Expect(first.Subject).To(Equal("add posts"))
+			Expect(first.AuthorName).To(Equal("author"))
+			Expect(first.AuthorEmail).To(Equal("author@example.com"))
+			Expect(first.AuthorDate.Equal(time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+
+			second := info.File(filepath.Join(repo, "content", "second.md"))
+			Expect(second).NotTo(BeNil())
+			Expect(second.Subject).To(Equal("fix second"))
+			Expect(second.AuthorDate.Equal(time.Date(2018, 2, 3, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+
+		It("has no info about untracked files", func() {
+			info, err := hugo.LoadGitInfo(filepath.Join(repo, "content"))
+			Expect(err).To(Succeed())
+			Expect(info.File(filepath.Join(repo, "content", "untracked.md"))).To(BeNil())
+		})
+
+		It("leaves out the commits that only changed lastmod", func() {
+			write("content/second.md", "---\ntitle: second\nlastmod: 2018-02-03T10:00:00Z\n---\nbody\n")
+			write("content/my post.md", "---\ntitle: my post\n---\n")
+			git("2018-03-04T10:00:00Z", "add", ".")
+			git("2018-03-04T10:00:00Z", "commit", "-q", "-m", "bump lastmod and add a post")
+
+			info, err := hugo.LoadGitInfo(filepath.Join(repo, "content"))
+			Expect(err).To(Succeed())
+
+			second := info.File(filepath.Join(repo, "content", "second.md"))
+			Expect(second).NotTo(BeNil())
+			Expect(second.Subject).To(Equal("fix second"))
+
+			post := info.File(filepath.Join(repo, "content", "my post.md"))
+			Expect(post).NotTo(BeNil())
+			Expect(post.Subject).To(Equal("bump lastmod and add a post"))
+		})
+
+		It("retrieves the history of a single file", func() {
+			info, err := hugo.LoadGitInfo(filepath.Join(repo, "content", "first.md"))
+			Expect(err).To(Succeed())
+			Expect(info.File(filepath.Join(repo, "content", "first.md"))).NotTo(BeNil())
+			Expect(info.File(filepath.Join(repo, "content", "second.md"))).To(BeNil())
+		})
+
+		It("fails outside of git repositories", func() {
+			dir, err := ioutil.TempDir("", "hugo-utils-no-git")
+			Expect(err).To(Succeed())
+			defer os.RemoveAll(dir)
+
+			_, err = hugo.LoadGitInfo(dir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("LastContentChange", func() {
+		It("leaves out the commits that only changed lastmod", func() {
+			write("content/second.md", "---\ntitle: second\nlastmod: 2018-02-03T10:00:00Z\n---\nbody\n")
+			git("2018-03-04T10:00:00Z", "commit", "-q", "-a", "-m", "bump lastmod")
+
+			file, err := hugo.LastContentChange(filepath.Join(repo, "content", "second.md"))
+			Expect(err).To(Succeed())
+			Expect(file).NotTo(BeNil())
+			Expect(file.Subject).To(Equal("fix second"))
+		})
+
+		It("considers commits that changed more than lastmod", func() {
+			write("content/second.md", "---\ntitle: second\nlastmod: 2018-02-03T10:00:00Z\n---\nnew body\n")
+			git("2018-03-04T10:00:00Z", "commit", "-q", "-a", "-m", "rewrite second")
+
+			file, err := hugo.LastContentChange(filepath.Join(repo, "content", "second.md"))
+			Expect(err).To(Succeed())
+			Expect(file.Subject).To(Equal("rewrite second"))
+		})
+
+		It("retrieves nothing for untracked files", func() {
+			file, err := hugo.LastContentChange(filepath.Join(repo, "content", "untracked.md"))
+			Expect(err).To(Succeed())
+			Expect(file).To(BeNil())
+		})
+	})
+
+	Describe("GatherPagesWithOptions", func() {
+		It("takes the last modification dates from the history", func() {
+			info, err := hugo.LoadGitInfo(filepath.Join(repo, "content"))
+			Expect(err).To(Succeed())
+
+			pages, err := hugo.GatherPagesWithOptions(filepath.Join(repo, "content"), hugo.GatherOptions{
+				GitInfo: info,
+			})
+			Expect(err).To(Succeed())
+			Expect(pages).To(HaveLen(3))
+
+			Expect(pages[0].GitInfo.Subject).To(Equal("add posts"))
+			Expect(pages[0].Dates.LastMod.Equal(time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+			Expect(pages[1].Dates.LastMod.Equal(time.Date(2018, 2, 3, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+
+			Expect(pages[2].GitInfo).To(BeNil())
+			Expect(pages[2].Dates.LastMod).To(Equal(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)))
+		})
+	})
+})
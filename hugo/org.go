@@ -0,0 +1,316 @@
+package hugo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// orgFrontMatterPrefix starts each of the lines of an
+// Org-mode front matter (e.g., `#+TITLE: my post`).
+var orgFrontMatterPrefix = []byte("#+")
+
+// orgKeyword is a `#+KEY: value` line of an Org-mode
+// front matter.
+type orgKeyword struct {
+	key   string
+	value string
+	line  int
+}
+
+// orgValueError indicates that the value of a keyword can't
+// be decoded into the field that it corresponds to.
+type orgValueError struct {
+	key  string
+	line int
+	err  error
+}
+
+// Error implements the error interface.
+func (e *orgValueError) Error() string {
+	return fmt.Sprintf("invalid value for %s: %s", e.key, e.err)
+}
+
+// dateLayouts are the layouts that dates written as text
+// are tried against (after dropping the brackets and the day
+// of the week of Org timestamps).
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseOrgKeywords retrieves the keywords of an Org-mode
+// front matter, with their keys lowercased.
+func parseOrgKeywords(data []byte) (keywords []orgKeyword) {
+	for i, line := range splitLinesKeepingEnds(data) {
+		text := strings.TrimRight(string(line), " \t\r\n")
+		if !strings.HasPrefix(text, string(orgFrontMatterPrefix)) {
+			continue
+		}
+
+		parts := strings.SplitN(text[len(orgFrontMatterPrefix):], ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		keywords = append(keywords, orgKeyword{
+			key:   strings.ToLower(strings.TrimSpace(parts[0])),
+			value: strings.TrimSpace(parts[1]),
+			line:  i + 1,
+		})
+	}
+
+	return
+}
+
+// orgEntries converts Org-mode keywords into front matter
+// entries just like Hugo does: keys ending in `[]` (and the
+// `tags`, `categories` and `aliases` keys) hold lists of
+// space-separated values, and repeated keys have their values
+// joined by new lines.
+func orgEntries(keywords []orgKeyword) (entries map[string]interface{}) {
+	entries = map[string]interface{}{}
+
+	for _, keyword := range keywords {
+		key := keyword.key
+
+		switch {
+		case strings.HasSuffix(key, "[]"):
+			entries[strings.TrimSuffix(key, "[]")] = orgList(keyword.value)
+		case key == "tags" || key == "categories" || key == "aliases":
+			entries[key] = orgList(keyword.value)
+		default:
+			if previous, ok := entries[key].(string); ok {
+				entries[key] = previous + "\n" + keyword.value
+				continue
+			}
+
+			entries[key] = keyword.value
+		}
+	}
+
+	return
+}
+
+// orgList splits a list of space-separated values.
+func orgList(value string) (list []interface{}) {
+	list = []interface{}{}
+	for _, item := range strings.Fields(value) {
+		list = append(list, item)
+	}
+
+	return
+}
+
+// unmarshalOrg decodes an Org-mode front matter into either
+// a `FrontMatter` or a map of entries.
+func unmarshalOrg(data []byte, v interface{}) (err error) {
+	var (
+		keywords = parseOrgKeywords(data)
+		entries  = orgEntries(keywords)
+	)
+
+	switch v := v.(type) {
+	case *map[string]interface{}:
+		*v = entries
+	case *FrontMatter:
+		err = v.assignOrgEntries(entries, keywords)
+	default:
+		err = errors.Errorf("can't decode org front matter into %T", v)
+	}
+
+	return
+}
+
+// assignOrgEntries sets the known fields of the front matter
+// from the entries of an Org-mode front matter, whose values
+// are all strings (or lists of strings).
+func (fm *FrontMatter) assignOrgEntries(entries map[string]interface{}, keywords []orgKeyword) (err error) {
+	var (
+		fields = reflect.ValueOf(fm).Elem()
+		t      = fields.Type()
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value, ok := entries[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+
+		err = assignOrgValue(fields.Field(i), value)
+		if err != nil {
+			err = &orgValueError{
+				key:  name,
+				line: orgKeywordLine(keywords, strings.ToLower(name)),
+				err:  err,
+			}
+			return
+		}
+	}
+
+	return
+}
+
+// assignOrgValue sets a field from the value of an Org-mode
+// keyword.
+func assignOrgValue(field reflect.Value, value interface{}) (err error) {
+	var text string
+
+	switch value := value.(type) {
+	case string:
+		text = value
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+		text = strings.Join(items, " ")
+	}
+
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(text)
+	case bool:
+		var b bool
+
+		b, err = strconv.ParseBool(text)
+		if err != nil {
+			err = errors.Errorf("%q is not a boolean", text)
+			return
+		}
+
+		field.SetBool(b)
+	case []string:
+		field.Set(reflect.ValueOf(strings.Fields(text)))
+	case time.Time:
+		var date time.Time
+
+		date, err = parseDate(text)
+		if err != nil {
+			return
+		}
+
+		field.Set(reflect.ValueOf(date))
+	default:
+		err = errors.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return
+}
+
+// parseDate parses a date written either in one of the usual
+// formats or as an Org timestamp (e.g., `<2018-01-02 Tue 10:00>`).
+func parseDate(text string) (date time.Time, err error) {
+	var parts []string
+
+	for _, part := range strings.Fields(strings.Trim(text, "<>[]")) {
+		if _, parseErr := time.Parse("Mon", part); parseErr == nil {
+			// a day of the week.
+			continue
+		}
+
+		parts = append(parts, part)
+	}
+
+	value := strings.Join(parts, " ")
+	for _, layout := range dateLayouts {
+		date, err = time.Parse(layout, value)
+		if err == nil {
+			return
+		}
+	}
+
+	err = errors.Errorf("%q is not a date", text)
+	return
+}
+
+// orgKeywordLine retrieves the line (1-based) of the last
+// keyword that defines a given key.
+func orgKeywordLine(keywords []orgKeyword, key string) (line int) {
+	for _, keyword := range keywords {
+		if strings.TrimSuffix(keyword.key, "[]") == key {
+			line = keyword.line
+		}
+	}
+
+	return
+}
+
+// marshalOrg encodes either a `FrontMatter` or a map of entries
+// as Org-mode keywords.
+//
+// Unlike the other formats, fields that have no value are left
+// out, as Org-mode has no way of writing empty lists or dates.
+func marshalOrg(v interface{}) (data []byte, err error) {
+	var buf bytes.Buffer
+
+	switch v := v.(type) {
+	case *FrontMatter:
+		var (
+			fields = reflect.ValueOf(v).Elem()
+			t      = fields.Type()
+		)
+
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" || isEmptyValue(fields.Field(i)) {
+				continue
+			}
+
+			writeOrgKeyword(&buf, name, fields.Field(i).Interface())
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			writeOrgKeyword(&buf, key, v[key])
+		}
+	default:
+		err = errors.Errorf("can't encode %T as org front matter", v)
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+// writeOrgKeyword writes the line(s) of a keyword.
+func writeOrgKeyword(buf *bytes.Buffer, key string, value interface{}) {
+	switch value := value.(type) {
+	case []string:
+		fmt.Fprintf(buf, "#+%s[]: %s\n", key, strings.Join(value, " "))
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		fmt.Fprintf(buf, "#+%s[]: %s\n", key, strings.Join(items, " "))
+	case time.Time:
+		fmt.Fprintf(buf, "#+%s: %s\n", key, value.Format(time.RFC3339))
+	case nil:
+		fmt.Fprintf(buf, "#+%s:\n", key)
+	default:
+		for _, line := range strings.Split(fmt.Sprint(value), "\n") {
+			fmt.Fprintf(buf, "#+%s: %s\n", key, line)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package hugo_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Org-mode front matter", func() {
+	var (
+		page *hugo.Page
+		err  error
+	)
+
+	BeforeEach(func() {
+		page, err = hugo.ParsePageFile("testdata/formats/page.org")
+	})
+
+	It("parses the keywords", func() {
+		Expect(err).To(Succeed())
+		Expect(page.Format).To(Equal(hugo.FrontMatterFormatOrg))
+		Expect(page.Title).To(Equal("org page"))
+		Expect(page.Date).To(Equal(time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)))
+		Expect(page.Tags).To(Equal([]string{"tag1", "tag2"}))
+		Expect(page.Draft).To(BeTrue())
+		Expect(page.Params).To(Equal(map[string]interface{}{
+			"series": "intro",
+		}))
+	})
+
+	It("leaves everything after the keywords as body", func() {
+		Expect(string(page.Body)).To(Equal("\n* Body\n"))
+	})
+
+	It("writes the keywords back", func() {
+		page.Tags = append(page.Tags, "tag3")
+
+		var buf bytes.Buffer
+		Expect(page.Write(&buf)).To(Succeed())
+		Expect(buf.String()).To(Equal(`#+title: org page
+#+date: 2018-01-02T00:00:00Z
+#+tags[]: tag1 tag2 tag3
+#+draft: true
+#+series: intro
+
+* Body
+`))
+
+		reparsed, err := hugo.ParsePage(&buf)
+		Expect(err).To(Succeed())
+		Expect(reparsed.FrontMatter).To(Equal(page.FrontMatter))
+	})
+})
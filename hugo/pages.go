@@ -3,12 +3,19 @@ package hugo
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
 )
 
 // Page represents a content page.
@@ -20,11 +27,70 @@ type Page struct {
 	// matter of the page.
 	FrontMatter `yaml:"-,inline"`
 
+	// Format is the language that the front matter
+	// was written in.
+	Format FrontMatterFormat `yaml:"-"`
+
+	// RawFrontMatter holds the front matter exactly as
+	// it was found in the page (without delimiters).
+	RawFrontMatter []byte `yaml:"-"`
+
+	// Markup is the language that the body is written
+	// in, as indicated by the extension of the page file.
+	Markup MarkupType `yaml:"-"`
+
+	// Kind tells whether the page is the index of a
+	// bundle (`index.md` or `_index.md`) or not.
+	Kind BundleKind `yaml:"-"`
+
+	// Section is the first directory under the content
+	// directory that holds the page (only known for pages
+	// that got gathered).
+	Section string `yaml:"-"`
+
+	// Resources are the paths to the files that belong to
+	// the bundle of the page (only known for pages that
+	// got gathered).
+	Resources []string `yaml:"-"`
+
+	// Language is the code of the language that the page is
+	// written in (only known for pages that got gathered).
+	Language string `yaml:"-"`
+
+	// Permalink is the URL that the page gets published at,
+	// relative to the base URL of the site (only known for
+	// pages that got gathered).
+	Permalink string `yaml:"-"`
+
+	// Dates are the dates that Hugo sees the page with,
+	// taken from the front matter or elsewhere as told by
+	// the site (only known for pages that got gathered).
+	Dates PageDates `yaml:"-"`
+
+	// GitInfo describes the last commit that touched the
+	// page file (only known for pages that got gathered
+	// with `GatherOptions.GitInfo`, and that have history).
+	GitInfo *GitFileInfo `yaml:"-"`
+
 	// Body contains the actual content of the page.
+	//
+	// Pages parsed with `ParsePageFileFrontMatter` only
+	// have it filled after `LoadBody` gets called.
 	Body []byte
-}
 
-var frontMatterDelim = []byte("---")
+	// lazy indicates that the body hasn't been loaded yet,
+	// living in the page file starting at `bodyOffset`.
+	lazy       bool
+	bodyOffset int64
+
+	// dateFromFilename indicates that some of the dates
+	// came from the date that prefixes the file name.
+	dateFromFilename bool
+
+	// layout keeps what surrounds the front matter
+	// in the page that got parsed.
+	layout pageLayout
+}
 
 type (
 	ParseState uint8
@@ -36,68 +102,243 @@ const (
 	ParseStateFrontMatter
 	ParseStateDelimEnd
 	ParseStateBody
+	ParseStateJSONFrontMatter
+	ParseStateOrgFrontMatter
 )
 
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// pageLayout holds the bytes that surround the front matter
+// of a page so that it can be written back exactly as it was.
+type pageLayout struct {
+	// format is the front matter format that the
+	// delimiters correspond to.
+	format FrontMatterFormat
+
+	// prefix contains whatever comes before the front
+	// matter (e.g., a byte order mark or blank lines).
+	prefix []byte
+
+	// opening and closing are the delimiter lines,
+	// including their line terminators.
+	opening []byte
+	closing []byte
+}
+
 // SplitFrontMatterAndBody takes a given reader and then
 // splits its content in two:
 // - FrontMatter
 // - Body
-func SplitFrontMatterAndBody(r io.Reader) (frontMatter, body []byte, err error) {
+//
+// The format of the front matter is detected from the
+// first delimiter found (`---` for YAML and `+++` for TOML),
+// from the page starting with a JSON object (`{`) or from
+// it starting with Org-mode keywords (`#+TITLE: ...`).
+//
+// Both parts are kept exactly as found in the content (line
+// endings included), and a page that doesn't start with front
+// matter has all of its content considered as body.
+func SplitFrontMatterAndBody(r io.Reader) (frontMatter, body []byte, format FrontMatterFormat, err error) {
 	if r == nil {
 		err = errors.Errorf(
 			"a reader must be specified")
 		return
 	}
 
-	var (
-		text            []byte
-		scanner         = bufio.NewScanner(r)
-		delimetersFound = 0
-		state           = ParseStateStart
-	)
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed while reading page")
+		return
+	}
 
-	body = make([]byte, 0)
-	frontMatter = make([]byte, 0)
+	frontMatter, body, layout, err := splitPage(content)
+	format = layout.format
 
-	for scanner.Scan() {
-		text = scanner.Bytes()
+	return
+}
 
-		if delimetersFound < 2 {
-			if bytes.Equal(frontMatterDelim, text) {
-				delimetersFound++
+// splitPage splits the content of a page into front matter
+// and body, retrieving the layout that surrounds them.
+//
+// Front matter that never gets terminated results in a
+// ParseError.
+func splitPage(content []byte) (frontMatter, body []byte, layout pageLayout, err error) {
+	var splitter pageSplitter
 
-				if delimetersFound == 1 {
-					state = ParseStateFrontMatter
-				} else {
-					state = ParseStateDelimEnd
-				}
-			}
+	for splitter.offset < len(content) && !splitter.step(content) {
+	}
+
+	frontMatter, body, layout, err = splitter.finish(content)
+	return
+}
+
+// pageSplitter goes through the lines of a page keeping
+// track of where its front matter starts and ends, so that
+// the content can be split (see `finish`) as soon as the
+// body is reached.
+type pageSplitter struct {
+	state         ParseState
+	format        FrontMatterFormat
+	jsonScanner   jsonObjectScanner
+	noFrontMatter bool
+
+	// prefixEnd is where the front matter (including
+	// its opening delimiter) starts.
+	prefixEnd int
+
+	// start and end delimit the front matter.
+	start, end int
+
+	// offset is where the next line to consume starts.
+	offset int
+}
+
+// step consumes the line of the content that starts at the
+// current offset, returning whether there's nothing left to
+// look for (the body got reached or there's no front matter).
+func (s *pageSplitter) step(content []byte) (done bool) {
+	if s.offset == 0 && bytes.HasPrefix(content, utf8BOM) {
+		s.offset = len(utf8BOM)
+	}
+
+	line := content[s.offset:]
+	if idx := bytes.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx+1]
+	}
+	text := bytes.TrimRight(line, " \t\r\n")
+
+	switch s.state {
+	case ParseStateStart:
+		if len(text) == 0 {
+			break
 		}
 
-		switch state {
-		case ParseStateBody:
-			body = append(body, text...)
-			body = append(body, '\n')
-		case ParseStateFrontMatter:
-			frontMatter = append(frontMatter, text...)
-			frontMatter = append(frontMatter, '\n')
-		case ParseStateDelimStart:
-			continue
-		case ParseStateDelimEnd:
-			state = ParseStateBody
+		if bytes.HasPrefix(text, jsonFrontMatterStart) {
+			s.format = FrontMatterFormatJSON
+			s.state = ParseStateJSONFrontMatter
+			s.prefixEnd = s.offset
+			s.start = s.offset
+			break
+		}
+
+		if bytes.HasPrefix(text, orgFrontMatterPrefix) {
+			s.format = FrontMatterFormatOrg
+			s.state = ParseStateOrgFrontMatter
+			s.prefixEnd = s.offset
+			s.start = s.offset
+			break
+		}
+
+		detected, ok := frontMatterFormatFromDelimiter(text)
+		if !ok {
+			// no front matter at all: the whole
+			// content is the body.
+			s.noFrontMatter = true
+			done = true
+			return
+		}
+
+		s.format = detected
+		s.state = ParseStateDelimStart
+	case ParseStateFrontMatter:
+		if bytes.Equal(s.format.delimiter(), text) {
+			s.state = ParseStateDelimEnd
+		}
+	case ParseStateOrgFrontMatter:
+		if !bytes.HasPrefix(text, orgFrontMatterPrefix) {
+			// the first line that isn't a keyword
+			// is already part of the body.
+			s.end = s.offset
+			s.state = ParseStateBody
+			done = true
+			return
 		}
 	}
 
-	err = scanner.Err()
-	if err != nil {
-		err = errors.Wrapf(err,
-			"failed while scanning page")
+	switch s.state {
+	case ParseStateStart:
+		s.offset += len(line)
+	case ParseStateJSONFrontMatter:
+		s.offset += len(line)
+
+		if s.jsonScanner.feed(text) {
+			s.end = s.offset
+			s.state = ParseStateBody
+		}
+	case ParseStateDelimStart:
+		s.prefixEnd = s.offset
+		s.offset += len(line)
+		s.start = s.offset
+		s.state = ParseStateFrontMatter
+	case ParseStateFrontMatter, ParseStateOrgFrontMatter:
+		s.offset += len(line)
+	case ParseStateDelimEnd:
+		s.end = s.offset
+		s.offset += len(line)
+		s.state = ParseStateBody
+	}
+
+	done = s.state == ParseStateBody
+	return
+}
+
+// finish splits the content according to what has been
+// found while stepping through its lines.
+func (s *pageSplitter) finish(content []byte) (frontMatter, body []byte, layout pageLayout, err error) {
+	layout.format = s.format
+
+	switch {
+	case s.noFrontMatter || s.state == ParseStateStart:
+		layout.format = FrontMatterFormatYAML
+		body = content
+		return
+	case s.state == ParseStateFrontMatter || s.state == ParseStateJSONFrontMatter:
+		line := lineOfOffset(content, s.prefixEnd)
+		err = &ParseError{
+			Kind:    ParseErrorUnterminated,
+			Line:    line,
+			Column:  1,
+			Snippet: string(bytes.TrimRight(nthLine(content, line), "\r\n")),
+			Err: errors.Errorf(
+				"%s front matter is never closed", s.format),
+		}
 		return
 	}
 
+	if s.state == ParseStateOrgFrontMatter {
+		// Org-mode front matter can go up to
+		// the end of the page.
+		s.end = s.offset
+	}
+
+	layout.prefix = content[:s.prefixEnd]
+	if s.format.delimiter() != nil {
+		layout.opening = content[s.prefixEnd:s.start]
+		layout.closing = content[s.end:s.offset]
+	}
+
+	frontMatter = content[s.start:s.end]
+	body = content[s.offset:]
 	return
 }
 
+// hasFrontMatter indicates whether a front matter
+// got found in the page.
+func (l pageLayout) hasFrontMatter() bool {
+	return l.opening != nil || l.format.delimiter() == nil
+}
+
+// crlf indicates whether the front matter of the page
+// uses `\r\n` line terminators.
+func (l pageLayout) crlf(frontMatter []byte) bool {
+	if l.opening != nil {
+		return bytes.HasSuffix(l.opening, []byte("\r\n"))
+	}
+
+	return bytes.Contains(frontMatter, []byte("\r\n"))
+}
+
 // Write writes the contents of a page to a given destination
 // writer.
 func (p *Page) Write(w io.Writer) (err error) {
@@ -106,55 +347,210 @@ func (p *Page) Write(w io.Writer) (err error) {
 		return
 	}
 
-	_, err = w.Write([]byte("---\n"))
+	err = p.LoadBody()
 	if err != nil {
-		err = errors.Wrapf(err, "failed to write openning front matter separator")
 		return
 	}
 
-	encoder := yaml.NewEncoder(w)
-	err = encoder.Encode(&p.FrontMatter)
+	var frontMatter bytes.Buffer
+
+	err = p.FrontMatter.encode(p.Format, &frontMatter)
 	if err != nil {
-		err = errors.Wrapf(err, "failed to encode frontmatter to document")
+		err = errors.Wrapf(err, "failed to encode %s frontmatter to document", p.Format)
 		return
 	}
 
-	err = encoder.Close()
+	err = p.write(w, withLineEndings(
+		frontMatter.Bytes(), p.layout.crlf(p.RawFrontMatter)))
+	return
+}
+
+// WritePreservingStyle writes the contents of a page to a given
+// destination writer just like `Write`, except that instead of
+// re-encoding the whole front matter, only the entries whose
+// values differ from the ones originally parsed get rewritten.
+//
+// Comments, key ordering, quoting and untouched entries are kept
+// as they were, and default values are not added, so writing an
+// unchanged page produces exactly the content it was parsed from.
+//
+// Editing entries in place is only possible for YAML front matter;
+// TOML and JSON front matter that changed is written as `Write`
+// would.
+func (p *Page) WritePreservingStyle(w io.Writer) (err error) {
+	if w == nil {
+		err = errors.Errorf("writer msut not be nil")
+		return
+	}
+
+	err = p.LoadBody()
 	if err != nil {
-		err = errors.Wrapf(err, "failed to close frontmatter encoder")
 		return
 	}
 
-	_, err = w.Write([]byte("---\n"))
+	var (
+		original    FrontMatter
+		frontMatter []byte
+		edits       []frontMatterEdit
+	)
+
+	err = original.decode(p.Format, p.RawFrontMatter)
 	if err != nil {
-		err = errors.Wrapf(err, "failed to write closing front matter separator")
+		err = errors.Wrapf(err, "failed to parse original front matter")
 		return
 	}
 
-	r := bytes.NewReader(p.Body)
-	_, err = io.Copy(w, r)
+	edits = p.FrontMatter.diff(&original)
+	if len(edits) == 0 {
+		err = p.write(w, p.RawFrontMatter)
+		return
+	}
+
+	if p.Format != FrontMatterFormatYAML {
+		err = p.Write(w)
+		return
+	}
+
+	frontMatter, err = editYAMLFrontMatter(p.RawFrontMatter, edits)
 	if err != nil {
-		err = errors.Wrapf(err, "failed to copy body to writer")
+		err = errors.Wrapf(err, "failed to edit front matter")
+		return
+	}
+
+	err = p.write(w, frontMatter)
+	return
+}
+
+// write writes an already encoded front matter surrounded by
+// the delimiters of the page format, followed by the body.
+//
+// The delimiters and whatever preceded them in the original
+// page are reused as long as the format didn't change.
+func (p *Page) write(w io.Writer, frontMatter []byte) (err error) {
+	var (
+		layout  = p.layout
+		newline = []byte("\n")
+	)
+
+	if layout.format != p.Format {
+		layout = pageLayout{format: p.Format, prefix: layout.prefix}
+	}
+
+	if layout.crlf(frontMatter) {
+		newline = []byte("\r\n")
+	}
+
+	hasFrontMatter := len(frontMatter) > 0 || layout.opening != nil
+	if hasFrontMatter && p.Format.delimiter() != nil {
+		if layout.opening == nil {
+			layout.opening = append(append([]byte{}, p.Format.delimiter()...), newline...)
+		}
+
+		if layout.closing == nil {
+			layout.closing = append(append([]byte{}, p.Format.delimiter()...), newline...)
+		}
+
+		if len(p.Body) > 0 && !bytes.HasSuffix(layout.closing, []byte("\n")) {
+			layout.closing = append(append([]byte{}, layout.closing...), newline...)
+		}
+	}
+
+	for _, part := range [][]byte{
+		layout.prefix,
+		layout.opening,
+		frontMatter,
+		layout.closing,
+		p.Body,
+	} {
+		_, err = w.Write(part)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to write page to writer")
+			return
+		}
+	}
+
+	return
+}
+
+// KeyLine retrieves the line (1-based) of the page where a
+// top-level front matter key is defined (regardless of its case),
+// or 0 if the front matter doesn't define it.
+func (p *Page) KeyLine(key string) (line int) {
+	if p.Format == FrontMatterFormatOrg {
+		line = orgKeywordLine(parseOrgKeywords(p.RawFrontMatter), strings.ToLower(key))
+	} else {
+		line, _ = keyLineAndColumn(p.RawFrontMatter, key)
+	}
+
+	if line == 0 {
 		return
 	}
 
+	line += bytes.Count(p.layout.prefix, []byte("\n")) +
+		bytes.Count(p.layout.opening, []byte("\n"))
 	return
 }
 
 // ParsePage parses the page contents.
 func ParsePage(r io.Reader) (page *Page, err error) {
-	front, body, err := SplitFrontMatterAndBody(r)
+	page, err = parsePage(r, false)
+	return
+}
+
+// parsePage parses the page contents, taking pages without front
+// matter as they are if it's optional (see `newPage`).
+func parsePage(r io.Reader, frontMatterOptional bool) (page *Page, err error) {
+	if r == nil {
+		err = errors.Errorf(
+			"a reader must be specified")
+		return
+	}
+
+	content, err := ioutil.ReadAll(r)
 	if err != nil {
 		err = errors.Wrapf(err,
-			"failed to split frommatter and body from content page")
+			"failed to read content page")
 		return
 	}
 
-	page = &Page{Body: body}
-	err = yaml.Unmarshal(front, &page.FrontMatter)
+	front, body, layout, err := splitPage(content)
 	if err != nil {
-		err = errors.Wrapf(err,
-			"failed to parse front matter from reader")
+		return
+	}
+
+	page, err = newPage(content, front, body, layout, frontMatterOptional)
+	return
+}
+
+// newPage creates a page out of the parts of its content,
+// decoding the front matter.
+//
+// Pages without front matter are only accepted if it's optional,
+// getting an empty one and having all of their content as body.
+func newPage(content, front, body []byte, layout pageLayout, frontMatterOptional bool) (page *Page, err error) {
+	if !layout.hasFrontMatter() && !frontMatterOptional {
+		err = &ParseError{
+			Kind:    ParseErrorMissingDelimiter,
+			Line:    1,
+			Column:  1,
+			Snippet: string(bytes.TrimRight(nthLine(content, 1), "\r\n")),
+			Err: errors.Errorf(
+				"page must start with front matter (`---`, `+++`, `{` or `#+`)"),
+		}
+		return
+	}
+
+	format := layout.format
+
+	page = &Page{
+		Body:           body,
+		Format:         format,
+		RawFrontMatter: front,
+		layout:         layout,
+	}
+	err = page.FrontMatter.decode(format, front)
+	if err != nil {
+		err = newFrontMatterError(err, content, layout, front)
 		return
 	}
 
@@ -162,7 +558,28 @@ func ParsePage(r io.Reader) (page *Page, err error) {
 }
 
 // ParsePageFile parses a single page given a filepath.
+//
+// Pages of markups other than Markdown (e.g., bare HTML files)
+// can go without front matter, as Hugo renders them as they are.
 func ParsePageFile(path string) (page *Page, err error) {
+	page, err = parsePageFile(path, parsePage)
+	return
+}
+
+// ParsePageFileFrontMatter parses a single page given a filepath,
+// reading the file only up to the end of its front matter.
+//
+// The body is left out of the page until `LoadBody` gets called,
+// which keeps metadata-only traversals of big sites from reading
+// (and holding in memory) the content of every page.
+func ParsePageFileFrontMatter(path string) (page *Page, err error) {
+	page, err = parsePageFile(path, parsePageFrontMatter)
+	return
+}
+
+// parsePageFile opens a page file and parses it with a given
+// parse function.
+func parsePageFile(path string, parse func(io.Reader, bool) (*Page, error)) (page *Page, err error) {
 	if path == "" {
 		err = errors.Errorf("path must be non-empty")
 		return
@@ -177,20 +594,157 @@ func ParsePageFile(path string) (page *Page, err error) {
 	}
 	defer file.Close()
 
-	page, err = ParsePage(file)
+	page, err = parsePageReader(path, file, parse)
+	return
+}
+
+// parsePageReader parses the contents of a page file with a given
+// parse function, recording the path in the page (or in the error
+// if it couldn't be parsed).
+func parsePageReader(path string, r io.Reader, parse func(io.Reader, bool) (*Page, error)) (page *Page, err error) {
+	markup, _ := DefaultContentTypes.markupOf(path)
+
+	page, err = parse(r, markup.frontMatterOptional())
 	if err != nil {
+		if parseErr, ok := err.(*ParseError); ok {
+			parseErr.Path = path
+			return
+		}
+
 		err = errors.Wrapf(err,
 			"failed to parse %s page content", path)
 		return
 	}
 	page.Path = path
+	page.Markup = markup
+	page.Kind = bundleKindOf(path)
+
+	return
+}
+
+// parsePageFrontMatter parses the front matter of a page,
+// reading line by line only up to the end of it.
+func parsePageFrontMatter(r io.Reader, frontMatterOptional bool) (page *Page, err error) {
+	var (
+		reader   = bufio.NewReader(r)
+		splitter pageSplitter
+		content  []byte
+		line     []byte
+	)
+
+	for {
+		line, err = reader.ReadBytes('\n')
+		content = append(content, line...)
+
+		if len(line) > 0 && splitter.step(content) {
+			break
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to read content page")
+			return
+		}
+	}
+
+	front, body, layout, err := splitter.finish(content)
+	if err != nil {
+		return
+	}
+
+	page, err = newPage(content, front, body, layout, frontMatterOptional)
+	if err != nil {
+		return
+	}
+
+	page.unloadBody(len(content) - len(body))
+	return
+}
+
+// unloadBody drops the body of the page, keeping where it
+// starts in the page file so that it can be loaded later.
+func (p *Page) unloadBody(offset int) {
+	p.Body = nil
+	p.lazy = true
+	p.bodyOffset = int64(offset)
+}
+
+// LoadBody reads the body of a page that got parsed with
+// `ParsePageFileFrontMatter` from its file. Pages that already
+// have their body loaded are left untouched.
+func (p *Page) LoadBody() (err error) {
+	if !p.lazy {
+		return
+	}
+
+	file, err := os.Open(p.Path)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to open file %s", p.Path)
+		return
+	}
+	defer file.Close()
+
+	_, err = file.Seek(p.bodyOffset, io.SeekStart)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to seek to the body of %s", p.Path)
+		return
+	}
+
+	body, err := ioutil.ReadAll(file)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to read the body of %s", p.Path)
+		return
+	}
+
+	p.Body = body
+	p.lazy = false
 
 	return
 }
 
 // DiscoverMarkdownPaths looks at the filesystem as indicated
-// by a root path and searches for markdown files that might live there.
+// by a root path and searches for content files (of any of the
+// extensions that Hugo treats as content) that might live there.
+//
+// Content files that live inside a leaf bundle (other than its
+// index) are resources of the bundle, not pages, so they're
+// left out.
 func DiscoverMarkdownPaths(root string) (paths []string, err error) {
+	paths, err = DiscoverContentPaths(root, DefaultContentTypes)
+	return
+}
+
+// DiscoverContentPaths looks at the filesystem as indicated by
+// a root path and searches for content files of the given types.
+func DiscoverContentPaths(root string, types ContentTypes) (paths []string, err error) {
+	paths = make([]string, 0)
+
+	err = walkContentPaths(context.Background(), root, contentFilter{types: types}, func(page discoveredPage) error {
+		paths = append(paths, page.path)
+		return nil
+	})
+	return
+}
+
+// discoveredPage is a page file found while walking the
+// filesystem, along with what's known about its bundle.
+type discoveredPage struct {
+	path      string
+	markup    MarkupType
+	kind      BundleKind
+	resources []string
+}
+
+// walkContentPaths walks the filesystem under a root path,
+// calling `fn` for each content page found (in lexical order).
+func walkContentPaths(ctx context.Context, root string, filter contentFilter, fn func(page discoveredPage) error) (err error) {
 	if root == "" {
 		err = errors.Errorf("a root must be specified")
 		return
@@ -203,9 +757,9 @@ func DiscoverMarkdownPaths(root string) (paths []string, err error) {
 		return
 	}
 
-	paths = make([]string, 0)
 	walkFunc := func(path string, info os.FileInfo, walkErr error) (err error) {
-		if err != nil {
+		if walkErr != nil {
+			err = walkErr
 			return
 		}
 
@@ -214,22 +768,61 @@ func DiscoverMarkdownPaths(root string) (paths []string, err error) {
 			return
 		}
 
+		err = ctx.Err()
+		if err != nil {
+			return
+		}
+
+		var (
+			page = discoveredPage{path: path}
+			ok   bool
+		)
+
 		if info.IsDir() {
+			page.path, err = findLeafBundleIndex(path, filter)
+			if err != nil || page.path == "" {
+				return
+			}
+
+			page.markup, _ = filter.markupOf(page.path)
+			page.kind = BundleKindLeaf
+			page.resources, err = leafBundleResources(path, page.path, filter)
+			if err != nil {
+				return
+			}
+
+			err = fn(page)
+			if err != nil {
+				return
+			}
+
+			// nothing under a leaf bundle is a page
+			// on its own.
+			err = filepath.SkipDir
 			return
 		}
 
-		if filepath.Ext(path) != ".md" {
+		page.markup, ok = filter.markupOf(path)
+		if !ok {
 			return
 		}
 
-		paths = append(paths, path)
+		page.kind = bundleKindOf(path)
+		if page.kind == BundleKindBranch {
+			page.resources, err = branchBundleResources(filepath.Dir(path), filter)
+			if err != nil {
+				return
+			}
+		}
+
+		err = fn(page)
 		return
 	}
 
 	err = filepath.Walk(root, walkFunc)
 	if err != nil {
 		err = errors.Wrapf(err,
-			"failed to search for markdown files under root %s",
+			"failed to search for content files under root %s",
 			root)
 		return
 	}
@@ -237,30 +830,235 @@ func DiscoverMarkdownPaths(root string) (paths []string, err error) {
 	return
 }
 
+// GatherOptions customizes how pages get gathered.
+type GatherOptions struct {
+	// KeepGoing makes gathering go through every page even
+	// if some of them fail to be parsed. In that case, the
+	// pages that succeeded are returned along with a
+	// `*GatherError` describing the ones that failed.
+	KeepGoing bool
+
+	// Workers is the number of pages that get parsed
+	// concurrently (defaults to the number of CPUs).
+	Workers int
+
+	// FrontMatterOnly makes pages get parsed without their
+	// bodies (see `ParsePageFileFrontMatter`).
+	FrontMatterOnly bool
+
+	// Cache, if set, is consulted before parsing each page
+	// and updated with the pages that had to be parsed.
+	Cache *PageCache
+
+	// ContentDir is the directory that the sections of the
+	// pages are relative to (defaults to the root path).
+	ContentDir string
+
+	// ContentTypes indicates which files are content pages
+	// (defaults to `DefaultContentTypes`).
+	ContentTypes ContentTypes
+
+	// IgnoreFiles are the patterns of the paths of the files
+	// that are to be left out (as in Hugo's `ignoreFiles`).
+	IgnoreFiles []*regexp.Regexp
+
+	// Permalinks holds the settings that the permalinks of
+	// the pages are computed from (defaults to the paths of
+	// the pages under the content directory).
+	Permalinks PermalinkOptions
+
+	// Dates indicates where the dates of the pages are taken
+	// from (defaults to `DefaultDateSources`).
+	Dates DateSources
+
+	// GitInfo, if set, provides the last commits of the page
+	// files, which the `:git` date source takes the last
+	// modification dates from.
+	GitInfo *GitInfo
+}
+
+// GatherError aggregates the errors of every page that
+// failed to be parsed while gathering pages.
+type GatherError struct {
+	// Errors contains one error for each failed page,
+	// in the order that the pages were discovered.
+	Errors []error
+}
+
+// Error implements the error interface.
+func (e *GatherError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("%d page(s) failed to be parsed: %s",
+		len(e.Errors), strings.Join(msgs, "; "))
+}
+
 // GatherPages gathers all content pages under a given root path
 // and parses their contents.
 func GatherPages(root string) (pages []*Page, err error) {
+	pages, err = GatherPagesWithOptions(root, GatherOptions{})
+	return
+}
+
+// GatherPagesWithOptions gathers all content pages under a given
+// root path and parses their contents according to the options
+// specified.
+func GatherPagesWithOptions(root string, opts GatherOptions) (pages []*Page, err error) {
+	pages, err = GatherPagesContext(context.Background(), root, opts)
+	return
+}
+
+// GatherPagesContext gathers all content pages under a given root
+// path, parsing them with a pool of workers while the discovery of
+// the rest of the pages is still going on.
+//
+// Pages are returned in the order they were discovered, regardless
+// of the number of workers. Cancelling the context stops both the
+// discovery and the parsing.
+func GatherPagesContext(ctx context.Context, root string, opts GatherOptions) (pages []*Page, err error) {
+	type job struct {
+		index int
+		discoveredPage
+	}
+
+	type result struct {
+		job
+		page *Page
+		err  error
+	}
+
 	var (
-		paths []string
-		page  *Page
+		workers    = opts.Workers
+		contentDir = opts.ContentDir
+		filter     = contentFilter{types: opts.ContentTypes, ignore: opts.IgnoreFiles}
+		parse      = ParsePageFile
+		jobs       = make(chan job)
+		results    = make(chan result)
+		discovered = make(chan error, 1)
+		collected  []result
+		wg         sync.WaitGroup
 	)
 
-	paths, err = DiscoverMarkdownPaths(root)
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	if contentDir == "" {
+		contentDir = root
+	}
+
+	contentDir, err = filepath.Abs(contentDir)
 	if err != nil {
 		err = errors.Wrapf(err,
-			"couldn't find content under %s", root)
+			"failed to resolve absolute path of %s", contentDir)
 		return
 	}
 
-	for _, path := range paths {
-		page, err = ParsePageFile(path)
-		if err != nil {
-			err = errors.Wrapf(err,
-				"failed to parse page %s", path)
+	err = opts.Permalinks.validate()
+	if err != nil {
+		return
+	}
+
+	switch {
+	case opts.Cache != nil:
+		parse = func(path string) (*Page, error) {
+			return opts.Cache.parsePageFile(path, opts.FrontMatterOnly)
+		}
+	case opts.FrontMatterOnly:
+		parse = ParsePageFileFrontMatter
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		defer close(jobs)
+
+		index := 0
+		discovered <- walkContentPaths(ctx, root, filter, func(page discoveredPage) error {
+			select {
+			case jobs <- job{index, page}:
+				index++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				page, err := parse(j.path)
+				if err == nil {
+					page.Markup = j.markup
+					page.Kind = j.kind
+					page.Section = sectionOf(contentDir, j.path, j.kind)
+					page.Resources = j.resources
+					page.GitInfo = opts.GitInfo.File(j.path)
+					page.Dates, page.dateFromFilename = opts.Dates.resolve(page)
+					page.Permalink, page.Language = opts.Permalinks.permalink(contentDir, page)
+				}
+
+				select {
+				case results <- result{j, page, err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		if r.err != nil && !opts.KeepGoing {
+			cancel()
+		}
+
+		collected = append(collected, r)
+	}
+
+	sort.Slice(collected, func(i, j int) bool {
+		return collected[i].index < collected[j].index
+	})
+
+	gatherErr := &GatherError{}
+	for _, r := range collected {
+		if r.err == nil {
+			pages = append(pages, r.page)
+			continue
+		}
+
+		if !opts.KeepGoing {
+			pages = nil
+			err = errors.Wrapf(r.err,
+				"failed to parse page %s", r.path)
 			return
 		}
 
-		pages = append(pages, page)
+		gatherErr.Errors = append(gatherErr.Errors, r.err)
+	}
+
+	err = <-discovered
+	if err != nil {
+		pages = nil
+		err = errors.Wrapf(err,
+			"couldn't find content under %s", root)
+		return
+	}
+
+	if len(gatherErr.Errors) > 0 {
+		err = gatherErr
 	}
 
 	return
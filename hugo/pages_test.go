@@ -2,8 +2,13 @@ package hugo_test
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 	"time"
 
 	"github.com/cirocosta/hugo-utils/hugo"
@@ -57,6 +62,23 @@ the body`),
 				Expect(err).To(Succeed())
 			})
 
+			Context("having been parsed from a crlf page", func() {
+				BeforeEach(func() {
+					var parsed *hugo.Page
+
+					parsed, err = hugo.ParsePageFile("testdata/roundtrip/crlf.md")
+					Expect(err).To(Succeed())
+
+					parsed.FrontMatter = page.FrontMatter
+					page = parsed
+				})
+
+				It("keeps the delimiters, line endings and body as they were", func() {
+					Expect(writer.String()).To(HavePrefix("---\r\ntitle: page title\r\n"))
+					Expect(writer.String()).To(HaveSuffix("draft: false\r\n---\r\nfirst line\r\n\r\nsecond line\r\n"))
+				})
+			})
+
 			It("gets propery written w/ default values", func() {
 				Expect(writer.String()).To(Equal(`---
 title: page title
@@ -75,9 +97,286 @@ draft: false
 this is
 the body`))
 			})
+
+			Context("having publish and expiry dates", func() {
+				BeforeEach(func() {
+					page.PublishDate = time.Date(2000, 2, 2, 0, 0, 0, 0, time.UTC)
+					page.ExpiryDate = time.Date(2001, 2, 2, 0, 0, 0, 0, time.UTC)
+				})
+
+				It("writes them in every format", func() {
+					Expect(writer.String()).To(ContainSubstring(
+						"publishDate: 2000-02-02T00:00:00Z\nexpiryDate: 2001-02-02T00:00:00Z\n"))
+
+					for format, entry := range map[hugo.FrontMatterFormat]string{
+						hugo.FrontMatterFormatTOML: "publishDate = 2000-02-02T00:00:00Z\n",
+						hugo.FrontMatterFormatJSON: `"publishDate": "2000-02-02T00:00:00Z",`,
+					} {
+						writer.Reset()
+						page.Format = format
+
+						Expect(page.Write(writer)).To(Succeed())
+						Expect(writer.String()).To(ContainSubstring(entry))
+					}
+				})
+			})
+
+			Context("having toml format", func() {
+				BeforeEach(func() {
+					page.Format = hugo.FrontMatterFormatTOML
+				})
+
+				It("succeeds", func() {
+					Expect(err).To(Succeed())
+				})
+
+				It("gets written w/ toml delimiters", func() {
+					Expect(writer.String()).To(Equal(`+++
+title = "page title"
+description = ""
+slug = ""
+image = ""
+date = 2000-02-01T12:30:00Z
+lastmod = 0001-01-01T00:00:00Z
+tags = ["tag1", "tag2"]
+draft = false
++++
+this is
+the body`))
+				})
+			})
+
+			Context("having json format", func() {
+				BeforeEach(func() {
+					page.Format = hugo.FrontMatterFormatJSON
+				})
+
+				It("succeeds", func() {
+					Expect(err).To(Succeed())
+				})
+
+				It("gets written as a leading json object", func() {
+					Expect(writer.String()).To(Equal(`{
+  "title": "page title",
+  "description": "",
+  "slug": "",
+  "image": "",
+  "date": "2000-02-01T12:30:00Z",
+  "lastmod": "0001-01-01T00:00:00Z",
+  "tags": [
+    "tag1",
+    "tag2"
+  ],
+  "categories": null,
+  "keywords": null,
+  "draft": false
+}
+this is
+the body`))
+				})
+			})
 		})
 	})
 
+	Describe("Page#WritePreservingStyle", func() {
+		var (
+			page     *hugo.Page
+			original []byte
+			writer   *bytes.Buffer
+			err      error
+		)
+
+		BeforeEach(func() {
+			original, err = ioutil.ReadFile("testdata/preserve/page.md")
+			Expect(err).To(Succeed())
+
+			page, err = hugo.ParsePage(bytes.NewReader(original))
+			Expect(err).To(Succeed())
+
+			writer = new(bytes.Buffer)
+		})
+
+		JustBeforeEach(func() {
+			err = page.WritePreservingStyle(writer)
+		})
+
+		Context("without changes", func() {
+			It("writes the page byte-for-byte", func() {
+				Expect(err).To(Succeed())
+				Expect(writer.String()).To(Equal(string(original)))
+			})
+		})
+
+		Context("having fields changed, added and removed", func() {
+			BeforeEach(func() {
+				page.Tags = []string{"tag3"}
+				page.Description = "new description"
+				page.Params["weight"] = 20
+				delete(page.Params, "aliases")
+			})
+
+			It("only touches the entries that changed", func() {
+				Expect(err).To(Succeed())
+				Expect(writer.String()).To(Equal(`---
+# the title comes first
+title: 'my "styled" page'   # keep this
+date: 2006-01-02
+series: "kubernetes"
+tags:
+- 'tag3'
+
+weight: 20
+description: new description
+---
+this is the body
+`))
+			})
+		})
+
+		Context("having a string changed", func() {
+			BeforeEach(func() {
+				page.Title = "renamed"
+			})
+
+			It("keeps quoting and comments of the entry", func() {
+				Expect(err).To(Succeed())
+				Expect(writer.String()).To(ContainSubstring(
+					"\ntitle: 'renamed' # keep this\n"))
+			})
+		})
+	})
+
+	Describe("Page#Write & Page#WritePreservingStyle w/ unchanged pages", func() {
+		files, err := ioutil.ReadDir("testdata/roundtrip")
+		if err != nil {
+			panic(err)
+		}
+
+		for _, file := range files {
+			file := filepath.Join("testdata/roundtrip", file.Name())
+
+			It("writes "+file+" back byte-for-byte", func() {
+				original, err := ioutil.ReadFile(file)
+				Expect(err).To(Succeed())
+
+				page, err := hugo.ParsePage(bytes.NewReader(original))
+				Expect(err).To(Succeed())
+
+				writer := new(bytes.Buffer)
+				Expect(page.WritePreservingStyle(writer)).To(Succeed())
+				Expect(writer.Bytes()).To(Equal(original))
+			})
+
+			It("writes the body of "+file+" back byte-for-byte", func() {
+				original, err := ioutil.ReadFile(file)
+				Expect(err).To(Succeed())
+
+				page, err := hugo.ParsePage(bytes.NewReader(original))
+				Expect(err).To(Succeed())
+
+				writer := new(bytes.Buffer)
+				Expect(page.Write(writer)).To(Succeed())
+
+				_, originalBody, _, err := hugo.SplitFrontMatterAndBody(bytes.NewReader(original))
+				Expect(err).To(Succeed())
+
+				_, body, _, err := hugo.SplitFrontMatterAndBody(bytes.NewReader(writer.Bytes()))
+				Expect(err).To(Succeed())
+				Expect(body).To(Equal(originalBody))
+			})
+		}
+	})
+
+	Describe("SplitFrontMatterAndBody", func() {
+		Context("having lines longer than a scanner token", func() {
+			var (
+				longLine = strings.Repeat("a", 256*1024)
+				body     []byte
+				err      error
+			)
+
+			BeforeEach(func() {
+				_, body, _, err = hugo.SplitFrontMatterAndBody(strings.NewReader(
+					"---\ntitle: 'long'\n---\n" + longLine))
+			})
+
+			It("succeeds", func() {
+				Expect(err).To(Succeed())
+			})
+
+			It("keeps the line as is", func() {
+				Expect(string(body)).To(Equal(longLine))
+			})
+		})
+
+		Context("having crlf line endings", func() {
+			It("keeps them in both parts", func() {
+				fm, body, _, err := hugo.SplitFrontMatterAndBody(strings.NewReader(
+					"---\r\ntitle: 'crlf'\r\n---\r\nbody\r\n"))
+				Expect(err).To(Succeed())
+				Expect(string(fm)).To(Equal("title: 'crlf'\r\n"))
+				Expect(string(body)).To(Equal("body\r\n"))
+			})
+		})
+
+		Context("not starting with front matter", func() {
+			It("considers everything as body", func() {
+				fm, body, _, err := hugo.SplitFrontMatterAndBody(strings.NewReader(
+					"text\n---\nnot: fm\n---\n"))
+				Expect(err).To(Succeed())
+				Expect(fm).To(BeEmpty())
+				Expect(string(body)).To(Equal("text\n---\nnot: fm\n---\n"))
+			})
+		})
+	})
+
+	Describe("ParsePageFile & Page#Write w/ unknown fields", func() {
+		for _, file := range []string{
+			"testdata/params/page-yaml.md",
+			"testdata/params/page-toml.md",
+			"testdata/params/page-json.md",
+		} {
+			file := file
+
+			Context("from "+file, func() {
+				var (
+					page    *hugo.Page
+					written *hugo.Page
+					err     error
+				)
+
+				BeforeEach(func() {
+					page, err = hugo.ParsePageFile(file)
+					Expect(err).To(Succeed())
+
+					writer := new(bytes.Buffer)
+					Expect(page.Write(writer)).To(Succeed())
+
+					written, err = hugo.ParsePage(writer)
+					Expect(err).To(Succeed())
+				})
+
+				It("keeps known fields typed", func() {
+					Expect(page.Title).To(Equal("with params"))
+					Expect(page.Params).ToNot(HaveKey("title"))
+				})
+
+				It("retains the unknown fields", func() {
+					Expect(page.Params).To(HaveLen(3))
+					Expect(page.Params).To(HaveKeyWithValue("series", "kubernetes"))
+					Expect(page.Params).To(HaveKey("weight"))
+					Expect(page.Params).To(HaveKey("aliases"))
+				})
+
+				It("writes the unknown fields back", func() {
+					Expect(written.Format).To(Equal(page.Format))
+					Expect(written.Title).To(Equal(page.Title))
+					Expect(written.Params).To(Equal(page.Params))
+				})
+			})
+		}
+	})
+
 	Describe("GatherPages", func() {
 		Context("with content directory having md pages w/ fm", func() {
 			var (
@@ -106,6 +405,195 @@ the body`))
 		})
 	})
 
+	Describe("GatherPagesWithOptions", func() {
+		Context("with content directory having a page w/out fm", func() {
+			var (
+				err   error
+				pages []*hugo.Page
+				opts  hugo.GatherOptions
+			)
+
+			JustBeforeEach(func() {
+				pages, err = hugo.GatherPagesWithOptions(
+					"testdata/content-with-page-without-fm", opts)
+			})
+
+			Context("not keeping going", func() {
+				It("fails", func() {
+					Expect(err).ToNot(Succeed())
+					Expect(pages).To(BeEmpty())
+				})
+			})
+
+			Context("keeping going", func() {
+				BeforeEach(func() {
+					opts.KeepGoing = true
+				})
+
+				It("returns the pages that could be parsed", func() {
+					Expect(pages).To(HaveLen(1))
+					Expect(pages[0].Path).To(Equal(
+						"testdata/content-with-page-without-fm/page1.md"))
+				})
+
+				It("aggregates the failures", func() {
+					Expect(err).To(BeAssignableToTypeOf(&hugo.GatherError{}))
+
+					gatherErr := err.(*hugo.GatherError)
+					Expect(gatherErr.Errors).To(HaveLen(1))
+					Expect(gatherErr.Errors[0].(*hugo.ParseError).Path).To(Equal(
+						"testdata/content-with-page-without-fm/page-without-fm.md"))
+				})
+			})
+		})
+	})
+
+	Describe("GatherPagesContext", func() {
+		var contentDir string
+
+		BeforeEach(func() {
+			var err error
+
+			contentDir, err = ioutil.TempDir("", "")
+			Expect(err).To(Succeed())
+			Expect(writeContentPages(contentDir, 50)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(contentDir)
+		})
+
+		Context("with several workers", func() {
+			It("keeps the discovery order", func() {
+				paths, err := hugo.DiscoverMarkdownPaths(contentDir)
+				Expect(err).To(Succeed())
+
+				pages, err := hugo.GatherPagesContext(context.Background(), contentDir, hugo.GatherOptions{
+					Workers: 8,
+				})
+				Expect(err).To(Succeed())
+				Expect(pages).To(HaveLen(len(paths)))
+
+				for i, page := range pages {
+					Expect(page.Path).To(Equal(paths[i]))
+				}
+			})
+		})
+
+		Context("parsing front matter only", func() {
+			It("leaves bodies out", func() {
+				pages, err := hugo.GatherPagesContext(context.Background(), contentDir, hugo.GatherOptions{
+					FrontMatterOnly: true,
+				})
+				Expect(err).To(Succeed())
+				Expect(pages).To(HaveLen(50))
+
+				for _, page := range pages {
+					Expect(page.Title).ToNot(BeEmpty())
+					Expect(page.Body).To(BeNil())
+				}
+			})
+		})
+
+		Context("with cancelled context", func() {
+			It("fails", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := hugo.GatherPagesContext(ctx, contentDir, hugo.GatherOptions{})
+				Expect(err).ToNot(Succeed())
+			})
+		})
+	})
+
+	Describe("Page#KeyLine", func() {
+		keyLine := func(path, key string) int {
+			page, err := hugo.ParsePageFile(path)
+			Expect(err).To(Succeed())
+
+			return page.KeyLine(key)
+		}
+
+		It("counts the lines that precede the front matter", func() {
+			Expect(keyLine("testdata/roundtrip/leading-blank-lines.md", "title")).To(Equal(4))
+		})
+
+		It("finds keys of toml and json front matter", func() {
+			Expect(keyLine("testdata/roundtrip/toml.md", "Title")).To(Equal(2))
+			Expect(keyLine("testdata/roundtrip/json.md", "weird")).To(Equal(3))
+		})
+
+		It("finds the first line of org keywords", func() {
+			Expect(keyLine("testdata/roundtrip/org.org", "tags")).To(Equal(3))
+			Expect(keyLine("testdata/roundtrip/org.org", "description")).To(Equal(5))
+		})
+
+		It("retrieves 0 for missing keys", func() {
+			Expect(keyLine("testdata/roundtrip/toml.md", "slug")).To(Equal(0))
+		})
+	})
+
+	Describe("ParsePageFileFrontMatter", func() {
+		files, err := filepath.Glob("testdata/roundtrip/*.md")
+		if err != nil {
+			panic(err)
+		}
+
+		for _, file := range append(files, "testdata/page1.md") {
+			file := file
+
+			Context("from "+file, func() {
+				var eager, lazy *hugo.Page
+
+				BeforeEach(func() {
+					var err error
+
+					eager, err = hugo.ParsePageFile(file)
+					Expect(err).To(Succeed())
+
+					lazy, err = hugo.ParsePageFileFrontMatter(file)
+					Expect(err).To(Succeed())
+				})
+
+				It("parses the same front matter without the body", func() {
+					Expect(lazy.Path).To(Equal(file))
+					Expect(lazy.Format).To(Equal(eager.Format))
+					Expect(lazy.FrontMatter).To(Equal(eager.FrontMatter))
+					Expect(lazy.RawFrontMatter).To(Equal(eager.RawFrontMatter))
+					Expect(lazy.Body).To(BeNil())
+				})
+
+				It("loads the body on demand", func() {
+					Expect(lazy.LoadBody()).To(Succeed())
+					Expect(string(lazy.Body)).To(Equal(string(eager.Body)))
+				})
+
+				It("writes the whole page", func() {
+					var expected, actual bytes.Buffer
+
+					Expect(eager.WritePreservingStyle(&expected)).To(Succeed())
+					Expect(lazy.WritePreservingStyle(&actual)).To(Succeed())
+					Expect(actual.String()).To(Equal(expected.String()))
+				})
+			})
+		}
+
+		Context("having malformed pages", func() {
+			It("fails just like ParsePageFile", func() {
+				files, err := filepath.Glob("testdata/errors/*.md")
+				Expect(err).To(Succeed())
+
+				for _, file := range files {
+					_, expected := hugo.ParsePageFile(file)
+					_, err := hugo.ParsePageFileFrontMatter(file)
+					Expect(err).To(BeAssignableToTypeOf(expected), file)
+					Expect(err.(*hugo.ParseError).Pretty()).To(Equal(
+						expected.(*hugo.ParseError).Pretty()), file)
+				}
+			})
+		})
+	})
+
 	Describe("DiscoverMarkdownPaths", func() {
 		Context("with empty root", func() {
 			It("fails", func() {
@@ -176,12 +664,53 @@ the body`))
 
 			Context("not having front matter", func() {
 				It("fails", func() {
-					filePath = "testdata/without-fm.md"
+					filePath = "testdata/without-fm"
 					page, err = hugo.ParsePageFile(filePath)
 					Expect(err).ToNot(Succeed())
 				})
 			})
 
+			Context("having malformed pages", func() {
+				for _, entry := range []struct {
+					file   string
+					kind   hugo.ParseErrorKind
+					line   int
+					column int
+				}{
+					{"without-front-matter.md", hugo.ParseErrorMissingDelimiter, 1, 1},
+					{"unterminated.md", hugo.ParseErrorUnterminated, 2, 1},
+					{"yaml-type-mismatch.md", hugo.ParseErrorTypeMismatch, 3, 7},
+					{"toml-type-mismatch.md", hugo.ParseErrorTypeMismatch, 3, 8},
+					{"json-type-mismatch.md", hugo.ParseErrorTypeMismatch, 3, 12},
+					{"yaml-syntax.md", hugo.ParseErrorSyntax, 4, 1},
+					{"org-type-mismatch.org", hugo.ParseErrorTypeMismatch, 2, 10},
+				} {
+					entry := entry
+
+					It("fails w/ a located "+entry.kind.String()+" for "+entry.file, func() {
+						filePath = filepath.Join("testdata/errors", entry.file)
+
+						_, err = hugo.ParsePageFile(filePath)
+						Expect(err).To(BeAssignableToTypeOf(&hugo.ParseError{}))
+
+						parseErr := err.(*hugo.ParseError)
+						Expect(parseErr.Path).To(Equal(filePath))
+						Expect(parseErr.Kind).To(Equal(entry.kind))
+						Expect(parseErr.Line).To(Equal(entry.line))
+						Expect(parseErr.Column).To(Equal(entry.column))
+						Expect(parseErr.Snippet).ToNot(BeEmpty())
+					})
+				}
+
+				It("renders the offending snippet", func() {
+					_, err = hugo.ParsePageFile("testdata/errors/yaml-type-mismatch.md")
+					Expect(err.(*hugo.ParseError).Pretty()).To(Equal(
+						"testdata/errors/yaml-type-mismatch.md:3:7: type mismatch: cannot unmarshal !!str `foo` into []string\n" +
+							"   3 | tags: foo\n" +
+							"     |       ^\n"))
+				})
+			})
+
 			Context("having front matter", func() {
 				BeforeEach(func() {
 					filePath = "testdata/page1.md"
@@ -204,7 +733,135 @@ the body`))
 				It("has front matter parsed", func() {
 					Expect(page.Title).To(Equal("my thing"))
 				})
+
+				It("has yaml format detected", func() {
+					Expect(page.Format).To(Equal(hugo.FrontMatterFormatYAML))
+				})
+			})
+
+			Context("having toml front matter", func() {
+				BeforeEach(func() {
+					filePath = "testdata/page1-toml.md"
+					page, err = hugo.ParsePageFile(filePath)
+				})
+
+				It("succeeds", func() {
+					Expect(err).To(Succeed())
+				})
+
+				It("has the body captured", func() {
+					Expect(string(page.Body)).To(Equal(`this is the body
+`))
+				})
+
+				It("has front matter parsed", func() {
+					Expect(page.Title).To(Equal("my toml thing"))
+					Expect(page.Date.Day()).To(Equal(2))
+					Expect(page.Tags).To(Equal([]string{"tag1", "tag2"}))
+				})
+
+				It("has toml format detected", func() {
+					Expect(page.Format).To(Equal(hugo.FrontMatterFormatTOML))
+				})
+			})
+
+			Context("having json front matter", func() {
+				BeforeEach(func() {
+					filePath = "testdata/page1-json.md"
+					page, err = hugo.ParsePageFile(filePath)
+				})
+
+				It("succeeds", func() {
+					Expect(err).To(Succeed())
+				})
+
+				It("has the body captured", func() {
+					Expect(string(page.Body)).To(Equal(`this is the body
+`))
+				})
+
+				It("has front matter parsed", func() {
+					Expect(page.Title).To(Equal("my {json} thing"))
+					Expect(page.Description).To(Equal(`you "bet"`))
+					Expect(page.Date.Day()).To(Equal(2))
+					Expect(page.Tags).To(Equal([]string{"tag1", "tag2"}))
+				})
+
+				It("has json format detected", func() {
+					Expect(page.Format).To(Equal(hugo.FrontMatterFormatJSON))
+				})
 			})
 		})
 	})
 })
+
+// writeContentPages populates a directory with a given
+// number of pages spread across a few sections.
+func writeContentPages(dir string, count int) (err error) {
+	for i := 0; i < count; i++ {
+		section := filepath.Join(dir, fmt.Sprintf("section%d", i%10))
+
+		err = os.MkdirAll(section, 0755)
+		if err != nil {
+			return
+		}
+
+		err = ioutil.WriteFile(
+			filepath.Join(section, fmt.Sprintf("page%d.md", i)),
+			[]byte(fmt.Sprintf(`---
+title: 'page %d'
+date: 2006-01-02
+tags:
+  - 'tag%d'
+---
+%s
+`, i, i%20, strings.Repeat("body of the page\n", 200))),
+			0644)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func BenchmarkGatherPagesContext(b *testing.B) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = writeContentPages(dir, 2000)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := hugo.GatherPagesContext(context.Background(), dir, hugo.GatherOptions{
+					Workers: workers,
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	for _, frontMatterOnly := range []bool{false, true} {
+		b.Run(fmt.Sprintf("front-matter-only=%t", frontMatterOnly), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_, err := hugo.GatherPagesContext(context.Background(), dir, hugo.GatherOptions{
+					FrontMatterOnly: frontMatterOnly,
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package hugo
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// PermalinkOptions holds the settings of a site that determine
+// the URLs that its pages get published at.
+type PermalinkOptions struct {
+	// Patterns maps sections to the permalink patterns of
+	// their pages (e.g., `posts` to `/:year/:slug/`).
+	Patterns map[string]string
+
+	// Languages are the codes of the languages of the site,
+	// which content files can be suffixed with (e.g., the
+	// `fr` of `about.fr.md`).
+	Languages []string
+
+	// DefaultLanguage is the language of the site that
+	// doesn't get a URL prefix (unless in a subdirectory).
+	DefaultLanguage string
+
+	// DefaultLanguageInSubdir makes the pages of the default
+	// language get its prefix too (e.g., `/en/about/`).
+	DefaultLanguageInSubdir bool
+
+	// ContentLanguage is the language of the pages whose
+	// file names don't indicate one (defaults to the
+	// default language).
+	ContentLanguage string
+}
+
+// permalinkAttribute matches the attributes of a permalink
+// pattern (e.g., `:year`).
+var permalinkAttribute = regexp.MustCompile(`:[a-z]+`)
+
+// permalinkAttributes are the attributes that patterns can
+// be made of, as supported by Hugo.
+var permalinkAttributes = map[string]func(p *Page, filename, slug string) string{
+	":year":      func(p *Page, _, _ string) string { return p.Dates.Date.Format("2006") },
+	":month":     func(p *Page, _, _ string) string { return p.Dates.Date.Format("01") },
+	":monthname": func(p *Page, _, _ string) string { return urlize(p.Dates.Date.Format("January")) },
+	":day":       func(p *Page, _, _ string) string { return p.Dates.Date.Format("02") },
+	":yearday":   func(p *Page, _, _ string) string { return p.Dates.Date.Format("002") },
+	":weekday":   func(p *Page, _, _ string) string { return strconv.Itoa(int(p.Dates.Date.Weekday())) },
+	":title":     func(p *Page, _, _ string) string { return urlize(p.Title) },
+	":section":   func(p *Page, _, _ string) string { return p.Section },
+	":filename":  func(_ *Page, filename, _ string) string { return urlize(filename) },
+	":slug": func(p *Page, _, slug string) string {
+		if slug != "" {
+			return urlize(slug)
+		}
+
+		return urlize(p.Title)
+	},
+	":slugorfilename": func(_ *Page, filename, slug string) string {
+		if slug != "" {
+			return urlize(slug)
+		}
+
+		return urlize(filename)
+	},
+}
+
+// validate makes sure that every pattern is made of
+// attributes that are known.
+func (o PermalinkOptions) validate() (err error) {
+	for section, pattern := range o.Patterns {
+		for _, attribute := range permalinkAttribute.FindAllString(pattern, -1) {
+			if _, ok := permalinkAttributes[attribute]; !ok {
+				err = errors.Errorf(
+					"unknown permalink attribute %s in pattern %s of section %s",
+					attribute, pattern, section)
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// language retrieves the language of a page given the name
+// of its file, indicating the name without the language.
+func (o PermalinkOptions) language(name string) (language, base string) {
+	base = strings.TrimSuffix(name, filepath.Ext(name))
+
+	if ext := filepath.Ext(base); ext != "" {
+		for _, code := range o.Languages {
+			if strings.EqualFold(ext[1:], code) {
+				language = code
+				base = strings.TrimSuffix(base, ext)
+				return
+			}
+		}
+	}
+
+	language = o.ContentLanguage
+	if language == "" {
+		language = o.DefaultLanguage
+	}
+
+	return
+}
+
+// permalink computes the URL (relative to the base URL of the
+// site) that a page gets published at, as well as its language.
+//
+// Just like Hugo, the `url` of the front matter takes precedence
+// over everything else, followed by the pattern of the section of
+// the page (for pages that aren't the index of a section) and by
+// the path of the page under the content directory (with the file
+// name replaced by the `slug`, if any).
+//
+// Pages whose dates come from their file names (`:filename`) have
+// what follows the date as their slug unless they have one.
+func (o PermalinkOptions) permalink(contentDir string, page *Page) (link, language string) {
+	var rel string
+
+	abs, err := filepath.Abs(page.Path)
+	if err == nil {
+		rel, err = filepath.Rel(contentDir, abs)
+	}
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(page.Path)
+	}
+
+	var (
+		dir      = path.Dir(filepath.ToSlash(rel))
+		slug     = page.Slug
+		filename string
+	)
+
+	language, filename = o.language(path.Base(filepath.ToSlash(rel)))
+
+	if url := page.url(); url != "" {
+		link = "/" + strings.TrimPrefix(url, "/")
+		return
+	}
+
+	switch page.Kind {
+	case BundleKindLeaf:
+		// leaf bundles are named after their directory.
+		dir, filename = path.Dir(dir), path.Base(dir)
+	case BundleKindBranch:
+		filename = ""
+	}
+
+	if matches := filenameDate.FindStringSubmatch(filename); slug == "" && page.dateFromFilename && matches != nil {
+		slug = matches[2]
+	}
+
+	pattern, ok := o.Patterns[page.Section]
+	switch {
+	case ok && page.Kind != BundleKindBranch:
+		link = permalinkAttribute.ReplaceAllStringFunc(pattern, func(attribute string) string {
+			return permalinkAttributes[attribute](page, filename, slug)
+		})
+	case page.Kind == BundleKindBranch:
+		link = dir + "/"
+	case slug != "":
+		link = dir + "/" + slug + "/"
+	default:
+		link = dir + "/" + filename + "/"
+	}
+
+	if language != "" && (language != o.DefaultLanguage || o.DefaultLanguageInSubdir) {
+		link = language + "/" + link
+	}
+
+	link = cleanURLPath(link)
+	return
+}
+
+// url retrieves the `url` entry of the front matter.
+func (fm *FrontMatter) url() string {
+	for key, value := range fm.Params {
+		if strings.EqualFold(key, "url") {
+			url, _ := value.(string)
+			return url
+		}
+	}
+
+	return ""
+}
+
+// cleanURLPath makes a path absolute and free of redundant
+// slashes, lowercasing it and replacing spaces with hyphens
+// as Hugo does by default.
+func cleanURLPath(p string) string {
+	trailingSlash := strings.HasSuffix(p, "/")
+
+	p = path.Clean("/" + p)
+	p = strings.ToLower(strings.Replace(p, " ", "-", -1))
+	if trailingSlash && p != "/" {
+		p += "/"
+	}
+
+	return p
+}
+
+// urlize converts a value into a path segment: lowercased, with
+// spaces replaced by hyphens and without punctuation.
+func urlize(s string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package hugo_test
+
+import (
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Permalinks", func() {
+	gather := func(opts hugo.PermalinkOptions) (pages map[string]*hugo.Page) {
+		gathered, err := hugo.GatherPagesWithOptions("testdata/permalinks", hugo.GatherOptions{
+			Permalinks: opts,
+		})
+		Expect(err).To(Succeed())
+
+		pages = map[string]*hugo.Page{}
+		for _, page := range gathered {
+			pages[page.Title] = page
+		}
+
+		return
+	}
+
+	permalinks := func(opts hugo.PermalinkOptions) (links map[string]string) {
+		links = map[string]string{}
+		for title, page := range gather(opts) {
+			links[title] = page.Permalink
+		}
+
+		return
+	}
+
+	It("defaults to the paths of the pages", func() {
+		Expect(permalinks(hugo.PermalinkOptions{})).To(Equal(map[string]string{
+			"home":           "/",
+			"about":          "/about/",
+			"à propos":       "/about.fr/",
+			"posts":          "/posts/",
+			"My First Post!": "/posts/first-post/",
+			"slugged":        "/posts/custom-slug/",
+			"my bundle":      "/notes/my-bundle/",
+			"some note":      "/notes/some-note/",
+			"guide":          "/docs/the-guide/",
+			"moved":          "/elsewhere/",
+		}))
+	})
+
+	It("follows the patterns of the sections", func() {
+		links := permalinks(hugo.PermalinkOptions{
+			Patterns: map[string]string{
+				"posts": "/:year/:month/:slug/",
+				"notes": "/:section/:filename/",
+			},
+		})
+
+		Expect(links["posts"]).To(Equal("/posts/"))
+		Expect(links["My First Post!"]).To(Equal("/2018/03/my-first-post/"))
+		Expect(links["slugged"]).To(Equal("/2019/12/custom-slug/"))
+		Expect(links["my bundle"]).To(Equal("/notes/my-bundle/"))
+		Expect(links["some note"]).To(Equal("/notes/some-note/"))
+		Expect(links["guide"]).To(Equal("/docs/the-guide/"))
+		Expect(links["moved"]).To(Equal("/elsewhere/"))
+	})
+
+	It("prefixes the pages of other languages", func() {
+		pages := gather(hugo.PermalinkOptions{
+			Languages:       []string{"en", "fr"},
+			DefaultLanguage: "en",
+		})
+
+		Expect(pages["about"].Permalink).To(Equal("/about/"))
+		Expect(pages["about"].Language).To(Equal("en"))
+		Expect(pages["à propos"].Permalink).To(Equal("/fr/about/"))
+		Expect(pages["à propos"].Language).To(Equal("fr"))
+	})
+
+	It("prefixes the default language when in a subdirectory", func() {
+		links := permalinks(hugo.PermalinkOptions{
+			Languages:               []string{"en", "fr"},
+			DefaultLanguage:         "en",
+			DefaultLanguageInSubdir: true,
+		})
+
+		Expect(links["home"]).To(Equal("/en/"))
+		Expect(links["about"]).To(Equal("/en/about/"))
+		Expect(links["à propos"]).To(Equal("/fr/about/"))
+	})
+
+	It("fails on unknown attributes", func() {
+		_, err := hugo.GatherPagesWithOptions("testdata/permalinks", hugo.GatherOptions{
+			Permalinks: hugo.PermalinkOptions{
+				Patterns: map[string]string{"posts": "/:author/:slug/"},
+			},
+		})
+		Expect(err).To(MatchError(ContainSubstring("unknown permalink attribute :author")))
+	})
+})
@@ -0,0 +1,168 @@
+package hugo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EvergreenKey is the front matter key that marks pages as
+// evergreen: pages that never go stale (e.g., an "about" page).
+const EvergreenKey = "evergreen"
+
+// StaleOptions indicates how long pages can go without being
+// modified before they need attention.
+type StaleOptions struct {
+	// MaxAge is how long pages can go without modifications
+	// unless their sections or tags say otherwise.
+	MaxAge time.Duration
+
+	// SectionMaxAges overrides `MaxAge` for the pages of
+	// some sections (keyed by their names).
+	SectionMaxAges map[string]time.Duration
+
+	// TagMaxAges overrides `MaxAge` (and the ones of the
+	// sections) for the pages classified with some tags (keyed
+	// by the tags, regardless of case). Pages with more than
+	// one of them get the shortest.
+	TagMaxAges map[string]time.Duration
+}
+
+// StalePage is a page that went without modifications for
+// longer than it should.
+type StalePage struct {
+	Page *Page
+
+	// Age is how long ago the page was last modified.
+	Age time.Duration
+
+	// MaxAge is how long the page could go without
+	// modifications.
+	MaxAge time.Duration
+}
+
+// Overdue retrieves how long ago the page went stale.
+func (p *StalePage) Overdue() time.Duration {
+	return p.Age - p.MaxAge
+}
+
+// FindStalePages looks for the pages that, by a given time, went
+// longer than they should without modifications (as told by their
+// last modification dates), ranking them by how long ago they went
+// stale (the longest first).
+//
+// Only pages that hold content (single pages and leaf bundles) are
+// considered, leaving drafts, evergreen pages and pages without
+// dates out.
+func FindStalePages(pages []*Page, opts StaleOptions, now time.Time) (stale []*StalePage) {
+	for _, page := range pages {
+		if page.Kind == BundleKindBranch || page.Draft || page.Evergreen() {
+			continue
+		}
+
+		lastMod := page.Dates.LastMod
+		if lastMod.IsZero() {
+			continue
+		}
+
+		var (
+			age    = now.Sub(lastMod)
+			maxAge = opts.maxAge(page)
+		)
+
+		if maxAge <= 0 || age <= maxAge {
+			continue
+		}
+
+		stale = append(stale, &StalePage{
+			Page:   page,
+			Age:    age,
+			MaxAge: maxAge,
+		})
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Overdue() > stale[j].Overdue()
+	})
+
+	return
+}
+
+// maxAge retrieves how long a page can go without
+// modifications.
+func (o StaleOptions) maxAge(page *Page) (maxAge time.Duration) {
+	maxAge = o.MaxAge
+	if sectionMaxAge, ok := o.SectionMaxAges[page.Section]; ok {
+		maxAge = sectionMaxAge
+	}
+
+	var tagged bool
+	for _, tag := range page.Terms("tags") {
+		for name, tagMaxAge := range o.TagMaxAges {
+			if !strings.EqualFold(name, tag) {
+				continue
+			}
+
+			if !tagged || tagMaxAge < maxAge {
+				maxAge = tagMaxAge
+			}
+			tagged = true
+		}
+	}
+
+	return
+}
+
+// Evergreen indicates whether the front matter marks the page
+// as evergreen (`evergreen: true`, regardless of the case of
+// the key).
+func (fm *FrontMatter) Evergreen() bool {
+	for key, value := range fm.Params {
+		if !strings.EqualFold(key, EvergreenKey) {
+			continue
+		}
+
+		evergreen, _ := strconv.ParseBool(fmt.Sprint(value))
+		return evergreen
+	}
+
+	return false
+}
+
+// ageUnits are the units of ages (besides the ones of
+// `time.ParseDuration`), in days.
+var ageUnits = map[string]int{
+	"d":  1,
+	"w":  7,
+	"mo": 30,
+	"y":  365,
+}
+
+// ageAmount matches the ages given as an amount of one of
+// `ageUnits` (e.g., `6mo`).
+var ageAmount = regexp.MustCompile(`^(\d+)(d|w|mo|y)$`)
+
+// ParseAge parses an amount of time given in days (`90d`), weeks
+// (`2w`), months (`6mo`, 30 days each) or years (`1y`, 365 days
+// each), or as understood by `time.ParseDuration` (e.g., `36h`).
+func ParseAge(s string) (d time.Duration, err error) {
+	if match := ageAmount.FindStringSubmatch(strings.TrimSpace(s)); match != nil {
+		amount, _ := strconv.Atoi(match[1])
+		d = time.Duration(amount*ageUnits[match[2]]) * 24 * time.Hour
+		return
+	}
+
+	d, err = time.ParseDuration(s)
+	if err != nil {
+		err = errors.Errorf(
+			"invalid age %s (expected e.g. 90d, 2w, 6mo or 1y)", s)
+		return
+	}
+
+	return
+}
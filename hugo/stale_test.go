@@ -0,0 +1,92 @@
+package hugo_test
+
+import (
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stale", func() {
+	const day = 24 * time.Hour
+
+	Describe("FindStalePages", func() {
+		var (
+			pages []*hugo.Page
+			now   = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			opts  = hugo.StaleOptions{
+				MaxAge:         365 * day,
+				SectionMaxAges: map[string]time.Duration{"notes": 730 * day},
+				TagMaxAges:     map[string]time.Duration{"kubernetes": 180 * day},
+			}
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			pages, err = hugo.GatherPages("testdata/stale")
+			Expect(err).To(Succeed())
+		})
+
+		It("ranks the pages that went stale by how long ago they did", func() {
+			var titles []string
+
+			stale := hugo.FindStalePages(pages, opts, now)
+			for _, page := range stale {
+				titles = append(titles, page.Page.Title)
+			}
+
+			Expect(titles).To(Equal([]string{"old", "k8s note", "k8s"}))
+		})
+
+		It("tells how long the pages could go without modifications", func() {
+			stale := hugo.FindStalePages(pages, opts, now)
+
+			Expect(stale[0].Age).To(Equal(731 * day))
+			Expect(stale[0].MaxAge).To(Equal(365 * day))
+			Expect(stale[0].Overdue()).To(Equal(366 * day))
+
+			// the tag takes precedence over the section.
+			Expect(stale[1].MaxAge).To(Equal(180 * day))
+		})
+
+		It("leaves everything out when pages can be as old as they want", func() {
+			Expect(hugo.FindStalePages(pages, hugo.StaleOptions{}, now)).To(BeEmpty())
+		})
+	})
+
+	Describe("Evergreen", func() {
+		It("tells whether the front matter marks the page as evergreen", func() {
+			page, err := hugo.ParsePageFile("testdata/stale/posts/evergreen.md")
+			Expect(err).To(Succeed())
+			Expect(page.Evergreen()).To(BeTrue())
+
+			page, err = hugo.ParsePageFile("testdata/stale/posts/old.md")
+			Expect(err).To(Succeed())
+			Expect(page.Evergreen()).To(BeFalse())
+		})
+	})
+
+	Describe("ParseAge", func() {
+		It("parses days, weeks, months and years", func() {
+			for s, expected := range map[string]time.Duration{
+				"90d": 90 * day,
+				"2w":  14 * day,
+				"6mo": 180 * day,
+				"1y":  365 * day,
+				"36h": 36 * time.Hour,
+			} {
+				d, err := hugo.ParseAge(s)
+				Expect(err).To(Succeed())
+				Expect(d).To(Equal(expected), s)
+			}
+		})
+
+		It("fails on anything else", func() {
+			_, err := hugo.ParseAge("6 months")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
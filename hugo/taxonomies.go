@@ -0,0 +1,115 @@
+package hugo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultTaxonomies are the taxonomies (by their plural names)
+// that Hugo sites have unless configured otherwise.
+var DefaultTaxonomies = []string{"tags", "categories"}
+
+// Taxonomy holds the terms of a taxonomy along with the
+// pages classified with each of them.
+type Taxonomy struct {
+	// Name is the plural name of the taxonomy (e.g., `tags`).
+	Name string
+
+	// Terms are the terms used by the pages, sorted by name.
+	Terms []*Term
+}
+
+// Term is one of the values of a taxonomy.
+type Term struct {
+	// Name is the term as first found in the pages.
+	Name string
+
+	// Pages are the pages classified with the term.
+	Pages []*Page
+}
+
+// Count retrieves the number of pages classified with
+// the term.
+func (t *Term) Count() int {
+	return len(t.Pages)
+}
+
+// NewTaxonomy gathers the terms of a taxonomy from a set of
+// pages, keeping the order of the pages for each term.
+//
+// As with Hugo, terms that only differ in case are the
+// same term.
+func NewTaxonomy(name string, pages []*Page) (taxonomy *Taxonomy) {
+	var terms = map[string]*Term{}
+
+	taxonomy = &Taxonomy{Name: name}
+
+	for _, page := range pages {
+		for _, name := range page.Terms(taxonomy.Name) {
+			key := strings.ToLower(name)
+
+			term, ok := terms[key]
+			if !ok {
+				term = &Term{Name: name}
+				terms[key] = term
+				taxonomy.Terms = append(taxonomy.Terms, term)
+			}
+
+			term.Pages = append(term.Pages, page)
+		}
+	}
+
+	sort.SliceStable(taxonomy.Terms, func(i, j int) bool {
+		return strings.ToLower(taxonomy.Terms[i].Name) < strings.ToLower(taxonomy.Terms[j].Name)
+	})
+
+	return
+}
+
+// Terms retrieves the terms that the front matter classifies a
+// page with in a given taxonomy (by its plural name, e.g., `tags`
+// or `series`).
+//
+// Taxonomies that aren't known fields (e.g., `tags`) are looked
+// up in the parameters, where either a list of terms or a single
+// term can be set.
+func (fm *FrontMatter) Terms(taxonomy string) (terms []string) {
+	var (
+		fields = reflect.ValueOf(fm).Elem()
+		t      = fields.Type()
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if !strings.EqualFold(name, taxonomy) {
+			continue
+		}
+
+		terms, _ = fields.Field(i).Interface().([]string)
+		return
+	}
+
+	for key, value := range fm.Params {
+		if !strings.EqualFold(key, taxonomy) {
+			continue
+		}
+
+		switch value := value.(type) {
+		case []interface{}:
+			for _, item := range value {
+				terms = append(terms, fmt.Sprint(item))
+			}
+		case []string:
+			terms = value
+		case nil:
+		default:
+			terms = []string{fmt.Sprint(value)}
+		}
+
+		return
+	}
+
+	return
+}
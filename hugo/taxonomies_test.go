@@ -0,0 +1,79 @@
+package hugo_test
+
+import (
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Taxonomies", func() {
+	var pages []*hugo.Page
+
+	BeforeEach(func() {
+		var err error
+
+		pages, err = hugo.GatherPages("testdata/taxonomies")
+		Expect(err).To(Succeed())
+	})
+
+	termsOf := func(taxonomy *hugo.Taxonomy) (terms map[string][]string) {
+		terms = map[string][]string{}
+		for _, term := range taxonomy.Terms {
+			for _, page := range term.Pages {
+				terms[term.Name] = append(terms[term.Name], page.Title)
+			}
+		}
+
+		return
+	}
+
+	Describe("Terms", func() {
+		It("retrieves the terms of known fields", func() {
+			Expect(pages[0].Terms("tags")).To(Equal([]string{"Go", "kubernetes"}))
+			Expect(pages[0].Terms("Categories")).To(Equal([]string{"dev"}))
+		})
+
+		It("retrieves lists of terms from the parameters", func() {
+			Expect(pages[0].Terms("series")).To(Equal([]string{"intro"}))
+			Expect(pages[2].Terms("authors")).To(Equal([]string{"ciro", "someone"}))
+		})
+
+		It("takes a single value as a single term", func() {
+			Expect(pages[0].Terms("authors")).To(Equal([]string{"ciro"}))
+			Expect(pages[1].Terms("series")).To(Equal([]string{"intro"}))
+		})
+
+		It("retrieves no terms for unknown taxonomies", func() {
+			Expect(pages[0].Terms("colors")).To(BeEmpty())
+		})
+	})
+
+	Describe("NewTaxonomy", func() {
+		It("groups the pages by term, regardless of case", func() {
+			taxonomy := hugo.NewTaxonomy("tags", pages)
+			Expect(taxonomy.Name).To(Equal("tags"))
+			Expect(termsOf(taxonomy)).To(Equal(map[string][]string{
+				"docker":     {"third"},
+				"Go":         {"first", "second"},
+				"kubernetes": {"first"},
+			}))
+		})
+
+		It("sorts the terms by name", func() {
+			taxonomy := hugo.NewTaxonomy("tags", pages)
+			Expect(taxonomy.Terms).To(HaveLen(3))
+			Expect(taxonomy.Terms[0].Name).To(Equal("docker"))
+			Expect(taxonomy.Terms[1].Name).To(Equal("Go"))
+			Expect(taxonomy.Terms[1].Count()).To(Equal(2))
+			Expect(taxonomy.Terms[2].Name).To(Equal("kubernetes"))
+		})
+
+		It("gathers custom taxonomies", func() {
+			Expect(termsOf(hugo.NewTaxonomy("authors", pages))).To(Equal(map[string][]string{
+				"ciro":    {"first", "third"},
+				"someone": {"third"},
+			}))
+		})
+	})
+})
@@ -0,0 +1,209 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TermMetadataKeys are the front matter entries that the pages
+// of taxonomy terms (`<taxonomy>/<term>/_index.md`) should set.
+var TermMetadataKeys = []string{"title", "description", "image"}
+
+// TermPage relates a term of a taxonomy to the page that holds
+// its metadata.
+type TermPage struct {
+	// Taxonomy is the plural name of the taxonomy.
+	Taxonomy string
+
+	// Name is the term as first found in the pages, or the
+	// directory of the page for terms that no page uses.
+	Name string
+
+	// Term is the term as used by the pages (nil if it's
+	// not used anymore).
+	Term *Term
+
+	// Page is the page that holds the metadata of the term
+	// (nil if there's none).
+	Page *Page
+
+	// Missing are the keys of `TermMetadataKeys` that the
+	// page doesn't set (all of them if there's no page).
+	Missing []string
+}
+
+// Unused indicates whether the term has a page but isn't used
+// by any page anymore.
+func (t *TermPage) Unused() bool {
+	return t.Term == nil
+}
+
+// Complete indicates whether the term is used and has every
+// entry of metadata.
+func (t *TermPage) Complete() bool {
+	return t.Term != nil && len(t.Missing) == 0
+}
+
+// Draft indicates whether the page of the term is a draft (e.g.,
+// one that got scaffolded and is yet to be filled in).
+func (t *TermPage) Draft() bool {
+	return t.Page != nil && t.Page.Draft
+}
+
+// Dir retrieves the name of the directory that holds the page
+// of the term (e.g., `kubernetes` for `tags/kubernetes/_index.md`),
+// which, as with Hugo, is the term made into a path segment.
+func (t *TermPage) Dir() string {
+	if t.Page != nil {
+		return filepath.Base(filepath.Dir(t.Page.Path))
+	}
+
+	return urlize(t.Name)
+}
+
+// Scaffold creates the missing page of the term under a content
+// directory, retrieving its path.
+//
+// The page is a draft titled after the term: Hugo leaves it out
+// (keeping the default page of the term) until the rest of the
+// metadata gets filled in and the draft is published.
+func (t *TermPage) Scaffold(contentDir string) (path string, err error) {
+	if t.Page != nil {
+		err = errors.Errorf("term %s of %s already has a page at %s",
+			t.Name, t.Taxonomy, t.Page.Path)
+		return
+	}
+
+	path = filepath.Join(contentDir, t.Taxonomy, t.Dir(), "_index.md")
+
+	page := &Page{
+		Path: path,
+		FrontMatter: FrontMatter{
+			Title: t.Name,
+			Draft: true,
+		},
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create directory %s", filepath.Dir(path))
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			err = errors.Errorf("%s already exists", path)
+			return
+		}
+
+		err = errors.Wrapf(err,
+			"failed to create %s", path)
+		return
+	}
+	defer file.Close()
+
+	// only the entries that are set get written, as there's
+	// no original front matter to keep.
+	err = page.WritePreservingStyle(file)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to write %s", path)
+		return
+	}
+
+	err = file.Close()
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to write %s", path)
+		return
+	}
+
+	return
+}
+
+// FindTermPages relates the terms of a taxonomy to the pages that
+// hold their metadata (the `_index` pages of the directories under
+// the one of the taxonomy), also retrieving the pages of terms that
+// aren't used anymore.
+//
+// The result is sorted by term, regardless of case.
+func FindTermPages(taxonomy *Taxonomy, pages []*Page) (termPages []*TermPage) {
+	var byDir = map[string]*TermPage{}
+
+	for _, term := range taxonomy.Terms {
+		termPage := &TermPage{
+			Taxonomy: taxonomy.Name,
+			Name:     term.Name,
+			Term:     term,
+			Missing:  TermMetadataKeys,
+		}
+
+		if _, ok := byDir[termPage.Dir()]; ok {
+			// e.g., `Go lang` and `go-lang` share a page.
+			continue
+		}
+
+		byDir[termPage.Dir()] = termPage
+		termPages = append(termPages, termPage)
+	}
+
+	for _, page := range pages {
+		if !isTermPage(taxonomy.Name, page) {
+			continue
+		}
+
+		dir := filepath.Base(filepath.Dir(page.Path))
+
+		termPage, ok := byDir[urlize(dir)]
+		if !ok {
+			termPage = &TermPage{Taxonomy: taxonomy.Name, Name: dir}
+			byDir[urlize(dir)] = termPage
+			termPages = append(termPages, termPage)
+		}
+
+		termPage.Page = page
+		termPage.Missing = page.missingTermMetadata()
+	}
+
+	sort.SliceStable(termPages, func(i, j int) bool {
+		return strings.ToLower(termPages[i].Name) < strings.ToLower(termPages[j].Name)
+	})
+
+	return
+}
+
+// isTermPage indicates whether a page holds the metadata of a
+// term of a taxonomy: it's the `_index` of a directory right
+// under the one of the taxonomy.
+func isTermPage(taxonomy string, page *Page) bool {
+	if page.Kind != BundleKindBranch || !strings.EqualFold(page.Section, taxonomy) {
+		return false
+	}
+
+	parent := filepath.Base(filepath.Dir(filepath.Dir(page.Path)))
+	return strings.EqualFold(parent, page.Section)
+}
+
+// missingTermMetadata retrieves the keys of `TermMetadataKeys`
+// that the front matter doesn't set.
+func (fm *FrontMatter) missingTermMetadata() (missing []string) {
+	values := map[string]string{
+		"title":       fm.Title,
+		"description": fm.Description,
+		"image":       fm.Image,
+	}
+
+	for _, key := range TermMetadataKeys {
+		if strings.TrimSpace(values[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,117 @@
+package hugo_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FindTermPages", func() {
+	var termPages []*hugo.TermPage
+
+	BeforeEach(func() {
+		pages, err := hugo.GatherPages("testdata/terms")
+		Expect(err).To(Succeed())
+
+		termPages = hugo.FindTermPages(hugo.NewTaxonomy("tags", pages), pages)
+	})
+
+	summaryOf := func(termPages []*hugo.TermPage) (summary map[string][]string) {
+		summary = map[string][]string{}
+		for _, termPage := range termPages {
+			summary[termPage.Name] = termPage.Missing
+		}
+
+		return
+	}
+
+	It("relates every term to its page, sorted by term", func() {
+		Expect(termPages).To(HaveLen(4))
+		Expect(termPages[0].Name).To(Equal("docker"))
+		Expect(termPages[1].Name).To(Equal("Go"))
+		Expect(termPages[2].Name).To(Equal("kubernetes"))
+		Expect(termPages[3].Name).To(Equal("Service Mesh"))
+
+		Expect(termPages[1].Page).NotTo(BeNil())
+		Expect(termPages[1].Page.Path).To(Equal("testdata/terms/tags/go/_index.md"))
+		Expect(termPages[1].Term.Count()).To(Equal(2))
+	})
+
+	It("tells the metadata that each term lacks", func() {
+		Expect(summaryOf(termPages)).To(Equal(map[string][]string{
+			"docker":       {"image"},
+			"Go":           nil,
+			"kubernetes":   {"description", "image"},
+			"Service Mesh": {"title", "description", "image"},
+		}))
+
+		Expect(termPages[1].Complete()).To(BeTrue())
+		Expect(termPages[2].Complete()).To(BeFalse())
+	})
+
+	It("tells the terms without pages", func() {
+		Expect(termPages[3].Page).To(BeNil())
+		Expect(termPages[3].Dir()).To(Equal("service-mesh"))
+	})
+
+	It("tells the pages of terms that aren't used", func() {
+		Expect(termPages[0].Unused()).To(BeTrue())
+		Expect(termPages[0].Complete()).To(BeFalse())
+		Expect(termPages[1].Unused()).To(BeFalse())
+	})
+
+	Describe("TermPage#Scaffold", func() {
+		var contentDir string
+
+		BeforeEach(func() {
+			var err error
+
+			contentDir, err = ioutil.TempDir("", "hugo-utils-terms")
+			Expect(err).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(contentDir)
+		})
+
+		It("creates the page of the term as a draft titled after it", func() {
+			path, err := termPages[3].Scaffold(contentDir)
+			Expect(err).To(Succeed())
+			Expect(path).To(Equal(filepath.Join(contentDir, "tags", "service-mesh", "_index.md")))
+
+			content, err := ioutil.ReadFile(path)
+			Expect(err).To(Succeed())
+			Expect(string(content)).To(Equal("---\ntitle: Service Mesh\ndraft: true\n---\n"))
+		})
+
+		It("makes the scaffolded pages tell they're drafts", func() {
+			_, err := termPages[3].Scaffold(contentDir)
+			Expect(err).To(Succeed())
+
+			pages, err := hugo.GatherPages(contentDir)
+			Expect(err).To(Succeed())
+
+			scaffolded := hugo.FindTermPages(hugo.NewTaxonomy("tags", pages), pages)
+			Expect(scaffolded).To(HaveLen(1))
+			Expect(scaffolded[0].Draft()).To(BeTrue())
+			Expect(scaffolded[0].Missing).To(Equal([]string{"description", "image"}))
+			Expect(termPages[3].Draft()).To(BeFalse())
+		})
+
+		It("refuses to replace existing pages", func() {
+			_, err := termPages[3].Scaffold(contentDir)
+			Expect(err).To(Succeed())
+
+			_, err = termPages[3].Scaffold(contentDir)
+			Expect(err).To(HaveOccurred())
+
+			_, err = termPages[1].Scaffold(contentDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
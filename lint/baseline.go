@@ -0,0 +1,185 @@
+package lint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Baseline holds the findings that are known (e.g., those of
+// legacy pages) so that only new ones get reported.
+//
+// Findings match the ones of the baseline regardless of their
+// lines, so that adding or removing lines above a key doesn't
+// make its finding new.
+type Baseline struct {
+	// Findings are the known findings, with their paths
+	// relative to the directory of the baseline file.
+	Findings []Finding `json:"findings"`
+
+	// path is where the baseline gets saved to.
+	path string
+}
+
+// NewBaseline creates a baseline that gets saved to a given path,
+// holding a set of findings.
+func NewBaseline(path string, findings []Finding) (baseline *Baseline) {
+	baseline = &Baseline{
+		Findings: []Finding{},
+		path:     path,
+	}
+
+	for _, finding := range findings {
+		finding.Path = baseline.relPath(finding.Path)
+		baseline.Findings = append(baseline.Findings, finding)
+	}
+
+	return
+}
+
+// LoadBaseline loads the baseline saved at a given path.
+func LoadBaseline(path string) (baseline *Baseline, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read baseline %s", path)
+		return
+	}
+
+	baseline = &Baseline{path: path}
+
+	err = json.Unmarshal(content, baseline)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse baseline %s", path)
+		return
+	}
+
+	return
+}
+
+// Save writes the baseline to its file.
+func (b *Baseline) Save() (err error) {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to encode baseline")
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(b.path), 0755)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create directory of baseline %s", b.path)
+		return
+	}
+
+	err = ioutil.WriteFile(b.path, append(content, '\n'), 0644)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write baseline %s", b.path)
+		return
+	}
+
+	return
+}
+
+// Filter retrieves the findings that the baseline doesn't know
+// about, along with how many were left out for being known.
+//
+// Each finding of the baseline accounts for a single finding: if
+// a page breaks a rule once more, the new finding is reported.
+func (b *Baseline) Filter(findings []Finding) (fresh []Finding, known int) {
+	var counts = b.counts()
+
+	for _, finding := range findings {
+		key := b.key(finding)
+		if counts[key] > 0 {
+			counts[key]--
+			known++
+			continue
+		}
+
+		fresh = append(fresh, finding)
+	}
+
+	return
+}
+
+// Prune removes the findings of the baseline that aren't among a
+// set of (current) findings anymore, retrieving how many got
+// removed.
+func (b *Baseline) Prune(findings []Finding) (pruned int) {
+	var (
+		current = map[string]int{}
+		kept    = []Finding{}
+	)
+
+	for _, finding := range findings {
+		current[b.key(finding)]++
+	}
+
+	for _, finding := range b.Findings {
+		key := findingKey(finding)
+		if current[key] == 0 {
+			pruned++
+			continue
+		}
+
+		current[key]--
+		kept = append(kept, finding)
+	}
+
+	b.Findings = kept
+	return
+}
+
+// counts retrieves how many findings of the baseline have
+// each key.
+func (b *Baseline) counts() (counts map[string]int) {
+	counts = map[string]int{}
+	for _, finding := range b.Findings {
+		counts[findingKey(finding)]++
+	}
+
+	return
+}
+
+// key identifies a finding in the baseline.
+func (b *Baseline) key(finding Finding) string {
+	finding.Path = b.relPath(finding.Path)
+	return findingKey(finding)
+}
+
+// relPath retrieves the path of a file relative to the directory
+// of the baseline (with forward slashes), or the path as given if
+// it can't be made relative.
+func (b *Baseline) relPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(b.path))
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// findingKey identifies a finding by what it is about, leaving
+// its line and severity out.
+func findingKey(finding Finding) string {
+	return strings.Join([]string{
+		finding.Rule,
+		finding.Path,
+		finding.Key,
+		finding.Message,
+	}, "\x00")
+}
@@ -0,0 +1,122 @@
+package lint_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cirocosta/hugo-utils/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Baseline", func() {
+	var (
+		dir      string
+		path     string
+		cwd      string
+		findings []lint.Finding
+	)
+
+	finding := func(rule, path string, line int) lint.Finding {
+		return lint.Finding{
+			Rule:     rule,
+			Severity: lint.SeverityError,
+			Path:     path,
+			Key:      "tags",
+			Line:     line,
+			Message:  rule + " message",
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		cwd, err = os.Getwd()
+		Expect(err).To(Succeed())
+
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).To(Succeed())
+
+		// symlinks (e.g., macOS' /tmp) would make
+		// relative paths not match.
+		dir, err = filepath.EvalSymlinks(dir)
+		Expect(err).To(Succeed())
+
+		path = filepath.Join(dir, "baseline", "baseline.json")
+		findings = []lint.Finding{
+			finding("missing-tags", filepath.Join(dir, "content", "a.md"), 1),
+			finding("missing-tags", filepath.Join(dir, "content", "b.md"), 2),
+			finding("missing-title", filepath.Join(dir, "content", "b.md"), 3),
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("gets saved with paths relative to its file", func() {
+		Expect(lint.NewBaseline(path, findings).Save()).To(Succeed())
+
+		baseline, err := lint.LoadBaseline(path)
+		Expect(err).To(Succeed())
+		Expect(baseline.Findings).To(HaveLen(3))
+		Expect(baseline.Findings[0].Path).To(Equal("../content/a.md"))
+	})
+
+	It("fails to load baselines that don't exist", func() {
+		_, err := lint.LoadBaseline(path)
+		Expect(err).ToNot(Succeed())
+	})
+
+	Describe("Filter", func() {
+		It("leaves the known findings out regardless of their lines", func() {
+			baseline := lint.NewBaseline(path, findings)
+
+			current := []lint.Finding{
+				finding("missing-tags", filepath.Join(dir, "content", "a.md"), 10),
+				finding("missing-tags", filepath.Join(dir, "content", "c.md"), 1),
+				finding("missing-title", filepath.Join(dir, "content", "b.md"), 3),
+			}
+
+			fresh, known := baseline.Filter(current)
+			Expect(known).To(Equal(2))
+			Expect(fresh).To(Equal(current[1:2]))
+		})
+
+		It("reports findings beyond the ones known", func() {
+			baseline := lint.NewBaseline(path, findings[:1])
+
+			fresh, known := baseline.Filter([]lint.Finding{findings[0], findings[0]})
+			Expect(known).To(Equal(1))
+			Expect(fresh).To(HaveLen(1))
+		})
+
+		It("matches findings with relative paths", func() {
+			Expect(os.Chdir(dir)).To(Succeed())
+			defer os.Chdir(cwd)
+
+			baseline := lint.NewBaseline(path, findings)
+
+			_, known := baseline.Filter([]lint.Finding{
+				finding("missing-tags", filepath.Join("content", "a.md"), 1),
+			})
+			Expect(known).To(Equal(1))
+		})
+	})
+
+	Describe("Prune", func() {
+		It("removes the findings that got fixed", func() {
+			baseline := lint.NewBaseline(path, findings)
+
+			pruned := baseline.Prune([]lint.Finding{
+				finding("missing-tags", filepath.Join(dir, "content", "b.md"), 5),
+				finding("missing-tags", filepath.Join(dir, "content", "c.md"), 1),
+			})
+			Expect(pruned).To(Equal(2))
+			Expect(baseline.Findings).To(HaveLen(1))
+			Expect(baseline.Findings[0].Path).To(Equal("../content/b.md"))
+		})
+	})
+})
@@ -0,0 +1,242 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/pkg/errors"
+)
+
+// Severity indicates how bad it is for a page to break a rule.
+type Severity uint8
+
+const (
+	// SeverityOff disables a rule.
+	SeverityOff Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityOff:
+		return "off"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity retrieves the severity that has a given name
+// (`off`, `info`, `warning` or `error`).
+func ParseSeverity(name string) (severity Severity, err error) {
+	for _, severity = range []Severity{
+		SeverityOff,
+		SeverityInfo,
+		SeverityWarning,
+		SeverityError,
+	} {
+		if severity.String() == strings.ToLower(name) {
+			return
+		}
+	}
+
+	err = errors.Errorf("unknown severity %s", name)
+	return
+}
+
+// MarshalText implements encoding.TextMarshaler so that
+// severities get encoded by their names.
+func (s Severity) MarshalText() (text []byte, err error) {
+	text = []byte(s.String())
+	return
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) (err error) {
+	*s, err = ParseSeverity(string(text))
+	return
+}
+
+// Problem is something wrong that a rule finds in a page.
+type Problem struct {
+	// Key is the front matter key that the problem is
+	// about (if any).
+	Key string
+
+	Message string
+}
+
+// Rule is a check that every page goes through.
+type Rule struct {
+	// ID names the rule (e.g., `missing-tags`).
+	ID string
+
+	// Description tells what the rule checks.
+	Description string
+
+	// Severity is the severity of the rule unless
+	// configured otherwise.
+	Severity Severity
+
+	// Check retrieves the problems of a page.
+	Check func(ctx *Context, page *hugo.Page) []Problem
+}
+
+// Context gives rules access to the rest of the pages that
+// get linted.
+type Context struct {
+	// Pages are all the pages that get linted.
+	Pages []*hugo.Page
+
+	// sections holds the index (`_index.md`) of each
+	// section, keyed by the name of the section.
+	sections map[string]*hugo.Page
+}
+
+// SectionIndex retrieves the page that lists a section (its
+// `_index.md`), or nil if the section has none.
+func (ctx *Context) SectionIndex(section string) *hugo.Page {
+	return ctx.sections[section]
+}
+
+// newContext creates the context of a set of pages.
+func newContext(pages []*hugo.Page) (ctx *Context) {
+	ctx = &Context{
+		Pages:    pages,
+		sections: map[string]*hugo.Page{},
+	}
+
+	for _, page := range pages {
+		if page.Kind != hugo.BundleKindBranch || page.Section == "" {
+			continue
+		}
+
+		// nested sections belong to the section of
+		// their top directory too: the shallowest
+		// index is the one of the section.
+		index, ok := ctx.sections[page.Section]
+		if !ok || len(page.Path) < len(index.Path) {
+			ctx.sections[page.Section] = page
+		}
+	}
+
+	return
+}
+
+// Finding is a problem found in a page by a rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+
+	// Path is the path to the page file.
+	Path string `json:"path"`
+
+	// Key is the front matter key that the finding is
+	// about (if any).
+	Key string `json:"key,omitempty"`
+
+	// Line is the line (1-based) of the page where the key
+	// is defined, or the first line if it isn't.
+	Line int `json:"line"`
+
+	Message string `json:"message"`
+}
+
+// Options holds the settings of a lint run.
+type Options struct {
+	// Severities overrides the severities of rules (keyed
+	// by their IDs), disabling those set to `SeverityOff`.
+	Severities map[string]Severity
+}
+
+// validate verifies that the options only refer to rules
+// that exist.
+func (o Options) validate() (err error) {
+	for id := range o.Severities {
+		if Lookup(id) == nil {
+			err = errors.Errorf("unknown lint rule %s", id)
+			return
+		}
+	}
+
+	return
+}
+
+// Lint checks a set of pages against every enabled rule,
+// retrieving the findings sorted by path, line and rule.
+func Lint(pages []*hugo.Page, opts Options) (findings []Finding, err error) {
+	err = opts.validate()
+	if err != nil {
+		return
+	}
+
+	ctx := newContext(pages)
+
+	for _, rule := range Rules() {
+		severity, ok := opts.Severities[rule.ID]
+		if !ok {
+			severity = rule.Severity
+		}
+
+		if severity == SeverityOff {
+			continue
+		}
+
+		for _, page := range pages {
+			for _, problem := range rule.Check(ctx, page) {
+				finding := Finding{
+					Rule:     rule.ID,
+					Severity: severity,
+					Path:     page.Path,
+					Key:      problem.Key,
+					Message:  problem.Message,
+				}
+
+				if problem.Key != "" {
+					finding.Line = page.KeyLine(problem.Key)
+				}
+
+				if finding.Line == 0 {
+					finding.Line = 1
+				}
+
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Rule < b.Rule
+	})
+
+	return
+}
+
+// Count retrieves how many findings have a given severity.
+func Count(findings []Finding, severity Severity) (count int) {
+	for _, finding := range findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+
+	return
+}
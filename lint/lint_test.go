@@ -0,0 +1,118 @@
+package lint_test
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/cirocosta/hugo-utils/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lint", func() {
+	var (
+		pages    []*hugo.Page
+		opts     lint.Options
+		findings []lint.Finding
+		err      error
+	)
+
+	BeforeEach(func() {
+		pages, err = hugo.GatherPagesWithOptions("testdata/content", hugo.GatherOptions{
+			FrontMatterOnly: true,
+		})
+		Expect(err).To(Succeed())
+
+		opts = lint.Options{}
+	})
+
+	JustBeforeEach(func() {
+		findings, err = lint.Lint(pages, opts)
+	})
+
+	// summarize describes findings as `<path>:<line> <rule> <severity>`.
+	summarize := func(findings []lint.Finding) (res []string) {
+		for _, finding := range findings {
+			res = append(res, fmt.Sprintf("%s:%d %s %s",
+				finding.Path, finding.Line, finding.Rule, finding.Severity))
+		}
+
+		return
+	}
+
+	It("reports the problems of the pages at the lines of their keys", func() {
+		Expect(err).To(Succeed())
+		Expect(summarize(findings)).To(Equal([]string{
+			"testdata/content/posts/sloppy.md:1 missing-description warning",
+			"testdata/content/posts/sloppy.md:1 missing-keywords warning",
+			"testdata/content/posts/sloppy.md:1 missing-tags error",
+			"testdata/content/posts/sloppy.md:1 missing-title error",
+			"testdata/content/posts/sloppy.md:2 draft-in-published-section warning",
+			"testdata/content/posts/sloppy.md:4 lastmod-before-date error",
+			"testdata/content/posts/sloppy.md:5 empty-slug error",
+		}))
+	})
+
+	It("describes the problems", func() {
+		Expect(findings[5].Key).To(Equal("lastmod"))
+		Expect(findings[5].Message).To(Equal(
+			"lastmod (2019-12-31) is before date (2020-01-02)"))
+		Expect(findings[4].Message).To(Equal(
+			"draft in published section posts"))
+	})
+
+	Context("with severities overridden", func() {
+		BeforeEach(func() {
+			opts.Severities = map[string]lint.Severity{
+				"missing-tags":        lint.SeverityWarning,
+				"missing-keywords":    lint.SeverityOff,
+				"missing-description": lint.SeverityOff,
+			}
+		})
+
+		It("uses them, leaving the rules that are off out", func() {
+			Expect(err).To(Succeed())
+			Expect(summarize(findings)[:2]).To(Equal([]string{
+				"testdata/content/posts/sloppy.md:1 missing-tags warning",
+				"testdata/content/posts/sloppy.md:1 missing-title error",
+			}))
+			Expect(lint.Count(findings, lint.SeverityError)).To(Equal(3))
+		})
+	})
+
+	Context("with an unknown rule", func() {
+		BeforeEach(func() {
+			opts.Severities = map[string]lint.Severity{"foo": lint.SeverityError}
+		})
+
+		It("fails", func() {
+			Expect(err).To(MatchError("unknown lint rule foo"))
+		})
+	})
+
+	Describe("ParseSeverity", func() {
+		It("parses the names of the severities", func() {
+			severity, err := lint.ParseSeverity("Warning")
+			Expect(err).To(Succeed())
+			Expect(severity).To(Equal(lint.SeverityWarning))
+
+			_, err = lint.ParseSeverity("fatal")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+
+	Describe("Rules", func() {
+		It("retrieves the rules sorted by ID", func() {
+			var ids []string
+			for _, rule := range lint.Rules() {
+				ids = append(ids, rule.ID)
+			}
+
+			Expect(ids).To(ContainElement("missing-tags"))
+			Expect(sort.StringsAreSorted(ids)).To(BeTrue())
+			Expect(lint.Lookup("missing-tags").Severity).To(Equal(lint.SeverityError))
+		})
+	})
+})
@@ -0,0 +1,410 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ReportFormat indicates how findings get reported.
+type ReportFormat uint8
+
+const (
+	// ReportFormatText reports each finding in a line of the
+	// form `<path>:<line>: <severity>: <message> (<rule>)`.
+	ReportFormatText ReportFormat = iota
+	ReportFormatJSON
+	ReportFormatSARIF
+	ReportFormatCheckstyle
+	ReportFormatJUnit
+)
+
+// reportTool is the name of the tool that findings are
+// reported as coming from.
+const reportTool = "hugo-utils"
+
+// String returns the name of the format.
+func (f ReportFormat) String() string {
+	switch f {
+	case ReportFormatText:
+		return "text"
+	case ReportFormatJSON:
+		return "json"
+	case ReportFormatSARIF:
+		return "sarif"
+	case ReportFormatCheckstyle:
+		return "checkstyle"
+	case ReportFormatJUnit:
+		return "junit"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseReportFormat retrieves the format that has a given name
+// (`text`, `json`, `sarif`, `checkstyle` or `junit`).
+func ParseReportFormat(name string) (format ReportFormat, err error) {
+	for _, format = range []ReportFormat{
+		ReportFormatText,
+		ReportFormatJSON,
+		ReportFormatSARIF,
+		ReportFormatCheckstyle,
+		ReportFormatJUnit,
+	} {
+		if format.String() == strings.ToLower(name) {
+			return
+		}
+	}
+
+	err = errors.Errorf("unknown report format %s", name)
+	return
+}
+
+// WriteReport writes findings in a given format.
+func WriteReport(w io.Writer, format ReportFormat, findings []Finding) (err error) {
+	switch format {
+	case ReportFormatText:
+		err = writeTextReport(w, findings)
+	case ReportFormatJSON:
+		err = writeJSONReport(w, findings)
+	case ReportFormatSARIF:
+		err = writeSARIFReport(w, findings)
+	case ReportFormatCheckstyle:
+		err = writeCheckstyleReport(w, findings)
+	case ReportFormatJUnit:
+		err = writeJUnitReport(w, findings)
+	default:
+		err = errors.Errorf("unknown report format %d", format)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write %s report", format)
+		return
+	}
+
+	return
+}
+
+func writeTextReport(w io.Writer, findings []Finding) (err error) {
+	for _, finding := range findings {
+		_, err = fmt.Fprintf(w, "%s:%d: %s: %s (%s)\n", finding.Path, finding.Line,
+			finding.Severity, finding.Message, finding.Rule)
+		if err != nil {
+			return
+		}
+	}
+
+	if len(findings) > 0 {
+		_, err = fmt.Fprintln(w)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "%d error(s), %d warning(s), %d info(s)\n",
+		Count(findings, SeverityError),
+		Count(findings, SeverityWarning),
+		Count(findings, SeverityInfo))
+	return
+}
+
+func writeJSONReport(w io.Writer, findings []Finding) (err error) {
+	if findings == nil {
+		findings = []Finding{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(findings)
+	return
+}
+
+// The parts of the SARIF (v2.1.0) log format that findings
+// get reported with.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string        `json:"id"`
+		ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// sarifLevels maps severities to the levels of SARIF results.
+var sarifLevels = map[Severity]string{
+	SeverityInfo:    "note",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+func writeSARIFReport(w io.Writer, findings []Finding) (err error) {
+	var (
+		run = sarifRun{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           reportTool,
+				InformationURI: "https://github.com/cirocosta/hugo-utils",
+				Rules:          []sarifRule{},
+			}},
+			Results: []sarifResult{},
+		}
+		ruleIndexes = map[string]int{}
+	)
+
+	for _, finding := range findings {
+		index, ok := ruleIndexes[finding.Rule]
+		if !ok {
+			rule := sarifRule{ID: finding.Rule}
+			if registered := Lookup(finding.Rule); registered != nil {
+				rule.ShortDescription = &sarifMessage{registered.Description}
+			}
+
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[finding.Rule] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    finding.Rule,
+			RuleIndex: index,
+			Level:     sarifLevels[finding.Severity],
+			Message:   sarifMessage{finding.Message},
+			Locations: []sarifLocation{{sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact(finding.Path),
+				Region:           sarifRegion{finding.Line},
+			}}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+	return
+}
+
+// sarifSourceRoot is the base that relative artifact locations
+// are resolved against: the root of the checkout that the tool
+// ran at (as code scanning tools understand it).
+const sarifSourceRoot = "%SRCROOT%"
+
+// sarifArtifact retrieves the location of a file, relative to
+// `sarifSourceRoot` unless its path is absolute.
+func sarifArtifact(path string) (location sarifArtifactLocation) {
+	location.URI = fileURI(path)
+	if !filepath.IsAbs(path) {
+		location.URIBaseID = sarifSourceRoot
+	}
+
+	return
+}
+
+// fileURI retrieves the URI that refers to a file: relative
+// paths are kept relative (to where the tool ran).
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if filepath.IsAbs(filepath.FromSlash(path)) {
+		if !strings.HasPrefix(path, "/") {
+			// e.g., `C:/...` on windows.
+			path = "/" + path
+		}
+
+		return "file://" + path
+	}
+
+	return path
+}
+
+// The parts of the checkstyle format that findings get
+// reported with.
+type (
+	checkstyleReport struct {
+		XMLName xml.Name         `xml:"checkstyle"`
+		Version string           `xml:"version,attr"`
+		Files   []checkstyleFile `xml:"file"`
+	}
+
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+
+	checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+)
+
+func writeCheckstyleReport(w io.Writer, findings []Finding) (err error) {
+	var (
+		report = checkstyleReport{Version: "4.3"}
+		files  = map[string]int{}
+	)
+
+	for _, finding := range findings {
+		index, ok := files[finding.Path]
+		if !ok {
+			index = len(report.Files)
+			files[finding.Path] = index
+			report.Files = append(report.Files, checkstyleFile{Name: finding.Path})
+		}
+
+		file := &report.Files[index]
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     finding.Line,
+			Severity: finding.Severity.String(),
+			Message:  finding.Message,
+			Source:   reportTool + "." + finding.Rule,
+		})
+	}
+
+	err = writeXML(w, report)
+	return
+}
+
+// The parts of the JUnit format that findings get reported
+// with: a suite for each file, with a failed test case for
+// each of its findings.
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string       `xml:"name,attr"`
+		ClassName string       `xml:"classname,attr"`
+		Failure   junitFailure `xml:"failure"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+func writeJUnitReport(w io.Writer, findings []Finding) (err error) {
+	var (
+		report = junitTestSuites{
+			Name:     reportTool,
+			Tests:    len(findings),
+			Failures: len(findings),
+		}
+		suites = map[string]int{}
+	)
+
+	for _, finding := range findings {
+		index, ok := suites[finding.Path]
+		if !ok {
+			index = len(report.Suites)
+			suites[finding.Path] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: finding.Path})
+		}
+
+		suite := &report.Suites[index]
+		suite.Tests++
+		suite.Failures++
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%s:%d %s", finding.Path, finding.Line, finding.Rule),
+			ClassName: finding.Path,
+			Failure: junitFailure{
+				Message: finding.Message,
+				Type:    finding.Severity.String(),
+				Text: fmt.Sprintf("%s:%d: %s (%s)",
+					finding.Path, finding.Line, finding.Message, finding.Rule),
+			},
+		})
+	}
+
+	err = writeXML(w, report)
+	return
+}
+
+// writeXML writes an indented XML document.
+func writeXML(w io.Writer, v interface{}) (err error) {
+	_, err = io.WriteString(w, xml.Header)
+	if err != nil {
+		return
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(v)
+	if err != nil {
+		return
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return
+}
@@ -0,0 +1,180 @@
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+
+	"github.com/cirocosta/hugo-utils/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	var (
+		findings = []lint.Finding{
+			{
+				Rule:     "missing-tags",
+				Severity: lint.SeverityError,
+				Path:     "content/posts/a.md",
+				Key:      "tags",
+				Line:     1,
+				Message:  "missing tags",
+			},
+			{
+				Rule:     "url-collision",
+				Severity: lint.SeverityWarning,
+				Path:     "content/posts/b.md",
+				Key:      "slug",
+				Line:     3,
+				Message:  "permalink /posts/b/ is also claimed by content/posts/c.md",
+			},
+		}
+		buf bytes.Buffer
+	)
+
+	write := func(format lint.ReportFormat, findings []lint.Finding) []byte {
+		buf.Reset()
+		Expect(lint.WriteReport(&buf, format, findings)).To(Succeed())
+
+		return buf.Bytes()
+	}
+
+	Describe("ParseReportFormat", func() {
+		It("parses the names of the formats", func() {
+			format, err := lint.ParseReportFormat("SARIF")
+			Expect(err).To(Succeed())
+			Expect(format).To(Equal(lint.ReportFormatSARIF))
+
+			_, err = lint.ParseReportFormat("xml")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+
+	Describe("text", func() {
+		It("writes a line for each finding and a summary", func() {
+			Expect(string(write(lint.ReportFormatText, findings))).To(Equal(`content/posts/a.md:1: error: missing tags (missing-tags)
+content/posts/b.md:3: warning: permalink /posts/b/ is also claimed by content/posts/c.md (url-collision)
+
+1 error(s), 1 warning(s), 0 info(s)
+`))
+		})
+	})
+
+	Describe("json", func() {
+		It("writes the findings, with severities by name", func() {
+			var decoded []lint.Finding
+
+			Expect(json.Unmarshal(write(lint.ReportFormatJSON, findings), &decoded)).To(Succeed())
+			Expect(decoded).To(Equal(findings))
+			Expect(buf.String()).To(ContainSubstring(`"severity": "warning"`))
+		})
+
+		It("writes an empty list when there are no findings", func() {
+			Expect(string(write(lint.ReportFormatJSON, nil))).To(Equal("[]\n"))
+		})
+	})
+
+	Describe("sarif", func() {
+		It("writes a result for each finding, describing the rules", func() {
+			var log struct {
+				Version string
+				Runs    []struct {
+					Tool struct {
+						Driver struct {
+							Rules []struct {
+								ID               string
+								ShortDescription *struct{ Text string }
+							}
+						}
+					}
+					Results []struct {
+						RuleID    string
+						RuleIndex int
+						Level     string
+						Message   struct{ Text string }
+						Locations []struct {
+							PhysicalLocation struct {
+								ArtifactLocation struct {
+									URI       string
+									URIBaseID string `json:"uriBaseId"`
+								}
+								Region           struct{ StartLine int }
+							}
+						}
+					}
+				}
+			}
+
+			Expect(json.Unmarshal(write(lint.ReportFormatSARIF, findings), &log)).To(Succeed())
+			Expect(log.Version).To(Equal("2.1.0"))
+			Expect(log.Runs).To(HaveLen(1))
+
+			var (
+				rules   = log.Runs[0].Tool.Driver.Rules
+				results = log.Runs[0].Results
+			)
+
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[0].ID).To(Equal("missing-tags"))
+			Expect(rules[0].ShortDescription.Text).To(Equal("pages must be tagged"))
+			Expect(rules[1].ShortDescription).To(BeNil())
+
+			Expect(results).To(HaveLen(2))
+			Expect(results[1].RuleID).To(Equal("url-collision"))
+			Expect(results[1].RuleIndex).To(Equal(1))
+			Expect(results[1].Level).To(Equal("warning"))
+			Expect(results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI).To(Equal("content/posts/b.md"))
+			Expect(results[1].Locations[0].PhysicalLocation.ArtifactLocation.URIBaseID).To(Equal("%SRCROOT%"))
+			Expect(results[1].Locations[0].PhysicalLocation.Region.StartLine).To(Equal(3))
+		})
+	})
+
+	Describe("checkstyle", func() {
+		It("writes the findings under their files", func() {
+			var report struct {
+				Files []struct {
+					Name   string `xml:"name,attr"`
+					Errors []struct {
+						Line     int    `xml:"line,attr"`
+						Severity string `xml:"severity,attr"`
+						Source   string `xml:"source,attr"`
+					} `xml:"error"`
+				} `xml:"file"`
+			}
+
+			Expect(xml.Unmarshal(write(lint.ReportFormatCheckstyle, findings), &report)).To(Succeed())
+			Expect(report.Files).To(HaveLen(2))
+			Expect(report.Files[1].Name).To(Equal("content/posts/b.md"))
+			Expect(report.Files[1].Errors[0].Line).To(Equal(3))
+			Expect(report.Files[1].Errors[0].Severity).To(Equal("warning"))
+			Expect(report.Files[1].Errors[0].Source).To(Equal("hugo-utils.url-collision"))
+		})
+	})
+
+	Describe("junit", func() {
+		It("writes a failed test case for each finding", func() {
+			var report struct {
+				Tests  int `xml:"tests,attr"`
+				Suites []struct {
+					Name  string `xml:"name,attr"`
+					Cases []struct {
+						Name    string `xml:"name,attr"`
+						Failure struct {
+							Message string `xml:"message,attr"`
+							Type    string `xml:"type,attr"`
+						} `xml:"failure"`
+					} `xml:"testcase"`
+				} `xml:"testsuite"`
+			}
+
+			Expect(xml.Unmarshal(write(lint.ReportFormatJUnit, findings), &report)).To(Succeed())
+			Expect(report.Tests).To(Equal(2))
+			Expect(report.Suites).To(HaveLen(2))
+			Expect(report.Suites[0].Cases[0].Name).To(Equal("content/posts/a.md:1 missing-tags"))
+			Expect(report.Suites[0].Cases[0].Failure.Message).To(Equal("missing tags"))
+			Expect(report.Suites[0].Cases[0].Failure.Type).To(Equal("error"))
+		})
+	})
+})
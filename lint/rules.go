@@ -0,0 +1,172 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+)
+
+// registry holds the rules that pages get checked against,
+// keyed by their IDs.
+var registry = map[string]*Rule{}
+
+// Register makes a rule available to `Lint`, panicking if
+// there's already a rule with the same ID.
+func Register(rule *Rule) {
+	if _, ok := registry[rule.ID]; ok {
+		panic("lint: rule " + rule.ID + " registered twice")
+	}
+
+	registry[rule.ID] = rule
+}
+
+// Rules retrieves every registered rule, sorted by ID.
+func Rules() (rules []*Rule) {
+	for _, rule := range registry {
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	return
+}
+
+// Lookup retrieves the rule that has a given ID, or nil if
+// there's none.
+func Lookup(id string) *Rule {
+	return registry[id]
+}
+
+func init() {
+	Register(&Rule{
+		ID:          "missing-title",
+		Description: "pages must have a title",
+		Severity:    SeverityError,
+		Check: contentCheck(func(page *hugo.Page) []Problem {
+			if strings.TrimSpace(page.Title) == "" {
+				return []Problem{{"title", "missing title"}}
+			}
+
+			return nil
+		}),
+	})
+
+	Register(&Rule{
+		ID:          "missing-description",
+		Description: "pages should have a description",
+		Severity:    SeverityWarning,
+		Check: contentCheck(func(page *hugo.Page) []Problem {
+			if strings.TrimSpace(page.Description) == "" {
+				return []Problem{{"description", "missing description"}}
+			}
+
+			return nil
+		}),
+	})
+
+	Register(&Rule{
+		ID:          "missing-tags",
+		Description: "pages must be tagged",
+		Severity:    SeverityError,
+		Check: contentCheck(func(page *hugo.Page) []Problem {
+			if len(page.Tags) == 0 {
+				return []Problem{{"tags", "missing tags"}}
+			}
+
+			return nil
+		}),
+	})
+
+	Register(&Rule{
+		ID:          "missing-keywords",
+		Description: "pages should have keywords",
+		Severity:    SeverityWarning,
+		Check: contentCheck(func(page *hugo.Page) []Problem {
+			if len(page.Keywords) == 0 {
+				return []Problem{{"keywords", "missing keywords"}}
+			}
+
+			return nil
+		}),
+	})
+
+	Register(&Rule{
+		ID:          "missing-date",
+		Description: "pages should have a date",
+		Severity:    SeverityWarning,
+		Check: contentCheck(func(page *hugo.Page) []Problem {
+			if page.Dates.Date.IsZero() {
+				return []Problem{{"date", "missing date"}}
+			}
+
+			return nil
+		}),
+	})
+
+	Register(&Rule{
+		ID:          "empty-slug",
+		Description: "slugs that are set must not be empty",
+		Severity:    SeverityError,
+		Check: func(ctx *Context, page *hugo.Page) []Problem {
+			if page.KeyLine("slug") != 0 && strings.TrimSpace(page.Slug) == "" {
+				return []Problem{{"slug", "empty slug"}}
+			}
+
+			return nil
+		},
+	})
+
+	Register(&Rule{
+		ID:          "lastmod-before-date",
+		Description: "pages can't be modified before their date",
+		Severity:    SeverityError,
+		Check: func(ctx *Context, page *hugo.Page) []Problem {
+			var dates = page.Dates
+
+			if dates.LastMod.IsZero() || !dates.LastMod.Before(dates.Date) {
+				return nil
+			}
+
+			return []Problem{{"lastmod", fmt.Sprintf(
+				"lastmod (%s) is before date (%s)",
+				dates.LastMod.Format("2006-01-02"), dates.Date.Format("2006-01-02"))}}
+		},
+	})
+
+	Register(&Rule{
+		ID:          "draft-in-published-section",
+		Description: "drafts should not live in sections that are published",
+		Severity:    SeverityWarning,
+		Check: func(ctx *Context, page *hugo.Page) []Problem {
+			if !page.Draft || page.Kind == hugo.BundleKindBranch || page.Section == "" {
+				return nil
+			}
+
+			// sections without an index are
+			// published too.
+			if index := ctx.SectionIndex(page.Section); index != nil && index.Draft {
+				return nil
+			}
+
+			return []Problem{{"draft", fmt.Sprintf(
+				"draft in published section %s", page.Section)}}
+		},
+	})
+}
+
+// contentCheck makes a check apply only to the pages that hold
+// content (single pages and leaf bundles), leaving the pages that
+// list sections out.
+func contentCheck(check func(page *hugo.Page) []Problem) func(*Context, *hugo.Page) []Problem {
+	return func(ctx *Context, page *hugo.Page) []Problem {
+		if page.Kind == hugo.BundleKindBranch {
+			return nil
+		}
+
+		return check(page)
+	}
+}
@@ -27,6 +27,13 @@ func main() {
 	app.Commands = []cli.Command{
 		commands.List,
 		commands.Update,
+		commands.New,
+		commands.Collisions,
+		commands.Lint,
+		commands.Baseline,
+		commands.Stale,
+		commands.Terms,
+		commands.Cache,
 	}
 
 	app.Run(os.Args)
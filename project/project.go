@@ -0,0 +1,165 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the name of the file that configures hugo-utils for
+// a project, living at the root of its site (or, for the settings
+// of a user, at their home directory).
+const FileName = ".hugo-utils.yaml"
+
+// Config holds the settings of hugo-utils for a project.
+type Config struct {
+	// Commands holds the default values of the flags of each
+	// command, keyed by the name of the command (e.g., `list` or
+	// `baseline create`) and then by the name of the flag.
+	Commands map[string]map[string]interface{} `yaml:"commands"`
+
+	// Templates holds named output templates (e.g., for the
+	// format of `list`).
+	Templates map[string]string `yaml:"templates"`
+
+	// Lint holds the settings of the lint rules.
+	Lint LintConfig `yaml:"lint"`
+
+	// FrontMatter holds the default values of front matter
+	// entries, which pages that don't set them get.
+	FrontMatter map[string]interface{} `yaml:"frontMatter"`
+
+	// Files are the files that the settings were loaded
+	// from, in order of precedence (lowest first).
+	Files []string `yaml:"-"`
+}
+
+// LintConfig holds the settings of the lint rules.
+type LintConfig struct {
+	// Rules maps the IDs of rules to their severities
+	// (`error`, `warning`, `info` or `off`).
+	Rules map[string]string `yaml:"rules"`
+}
+
+// UserFile retrieves the path to the file that holds the
+// settings of the user (`~/.hugo-utils.yaml`), or "" if the
+// home directory of the user is unknown.
+func UserFile() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+
+	if home == "" {
+		return ""
+	}
+
+	return filepath.Join(home, FileName)
+}
+
+// Load loads the settings of the user and those of the project
+// whose site lives at a given root (if any), with the project's
+// taking precedence.
+func Load(root string) (config *Config, err error) {
+	var files = []string{UserFile()}
+
+	if root != "" {
+		files = append(files, filepath.Join(root, FileName))
+	}
+
+	config, err = LoadFiles(files...)
+	return
+}
+
+// LoadFiles loads the settings of a set of files, with the settings
+// of each file taking precedence over the ones of the files that
+// come before it. Files that don't exist are left out.
+func LoadFiles(paths ...string) (config *Config, err error) {
+	config = &Config{}
+
+	for _, path := range paths {
+		var (
+			content []byte
+			file    Config
+		)
+
+		if path == "" {
+			continue
+		}
+
+		content, err = ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				err = nil
+				continue
+			}
+
+			err = errors.Wrapf(err,
+				"failed to read configuration file %s", path)
+			return
+		}
+
+		err = yaml.UnmarshalStrict(content, &file)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to parse configuration file %s", path)
+			return
+		}
+
+		config.merge(&file)
+		config.Files = append(config.Files, path)
+	}
+
+	return
+}
+
+// CommandDefaults retrieves the default values of the flags of a
+// command (keyed by the names of the flags).
+func (c *Config) CommandDefaults(command string) map[string]interface{} {
+	return c.Commands[command]
+}
+
+// merge merges the settings of another configuration into the
+// configuration, with the other's taking precedence.
+func (c *Config) merge(other *Config) {
+	for command, flags := range other.Commands {
+		if c.Commands == nil {
+			c.Commands = map[string]map[string]interface{}{}
+		}
+
+		if c.Commands[command] == nil {
+			c.Commands[command] = map[string]interface{}{}
+		}
+
+		for flag, value := range flags {
+			c.Commands[command][flag] = value
+		}
+	}
+
+	for name, template := range other.Templates {
+		if c.Templates == nil {
+			c.Templates = map[string]string{}
+		}
+
+		c.Templates[name] = template
+	}
+
+	for id, severity := range other.Lint.Rules {
+		if c.Lint.Rules == nil {
+			c.Lint.Rules = map[string]string{}
+		}
+
+		c.Lint.Rules[id] = severity
+	}
+
+	for key, value := range other.FrontMatter {
+		if c.FrontMatter == nil {
+			c.FrontMatter = map[string]interface{}{}
+		}
+
+		c.FrontMatter[key] = value
+	}
+}
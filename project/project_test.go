@@ -0,0 +1,105 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cirocosta/hugo-utils/project"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Project", func() {
+	Describe("LoadFiles", func() {
+		var (
+			config *project.Config
+			err    error
+		)
+
+		BeforeEach(func() {
+			config, err = project.LoadFiles(
+				"testdata/home/.hugo-utils.yaml", "testdata/site/.hugo-utils.yaml")
+		})
+
+		It("merges the files, with the later ones taking precedence", func() {
+			Expect(err).To(Succeed())
+			Expect(config.Files).To(Equal([]string{
+				"testdata/home/.hugo-utils.yaml", "testdata/site/.hugo-utils.yaml",
+			}))
+
+			Expect(config.CommandDefaults("list")).To(Equal(map[string]interface{}{
+				"sort":  "date",
+				"draft": false,
+				"kind":  []interface{}{"single", "leaf"},
+			}))
+			Expect(config.CommandDefaults("lint")).To(Equal(map[string]interface{}{
+				"output": "sarif",
+			}))
+			Expect(config.CommandDefaults("update")).To(BeEmpty())
+
+			Expect(config.Templates).To(Equal(map[string]string{
+				"titles":   "{{ .Title }} ({{ .Section }})",
+				"untagged": "{{ if eq (len .Tags) 0 }}{{ .Path }}{{ end }}",
+			}))
+		})
+
+		It("keeps severities as they're written", func() {
+			Expect(config.Lint.Rules).To(Equal(map[string]string{
+				"missing-keywords":    "off",
+				"missing-description": "error",
+			}))
+		})
+
+		It("merges the front matter defaults", func() {
+			Expect(config.FrontMatter).To(Equal(map[string]interface{}{
+				"author": "someone",
+				"tags":   []interface{}{},
+				"draft":  true,
+			}))
+		})
+
+		It("leaves files that don't exist out", func() {
+			config, err := project.LoadFiles("testdata/nope.yaml", "", "testdata/home/.hugo-utils.yaml")
+			Expect(err).To(Succeed())
+			Expect(config.Files).To(Equal([]string{"testdata/home/.hugo-utils.yaml"}))
+		})
+
+		It("fails on unknown settings", func() {
+			_, err := project.LoadFiles("testdata/invalid.yaml")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+
+	Describe("Load", func() {
+		var home string
+
+		BeforeEach(func() {
+			home = os.Getenv("HOME")
+
+			abs, err := filepath.Abs("testdata/home")
+			Expect(err).To(Succeed())
+			Expect(os.Setenv("HOME", abs)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Setenv("HOME", home)
+		})
+
+		It("loads the file of the site over the one of the user", func() {
+			config, err := project.Load("testdata/site")
+			Expect(err).To(Succeed())
+			Expect(config.Files).To(Equal([]string{
+				project.UserFile(),
+				filepath.Join("testdata", "site", ".hugo-utils.yaml"),
+			}))
+			Expect(config.CommandDefaults("list")["sort"]).To(Equal("date"))
+		})
+
+		It("loads only the file of the user without a site", func() {
+			config, err := project.Load("")
+			Expect(err).To(Succeed())
+			Expect(config.CommandDefaults("list")["sort"]).To(Equal("title"))
+		})
+	})
+})
@@ -0,0 +1,210 @@
+package site
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/pkg/errors"
+)
+
+// defaultArchetype is what new content gets created from when
+// the site has no archetype for it, just like in Hugo.
+const defaultArchetype = `---
+title: "{{ replace .Name "-" " " | title }}"
+date: {{ .Date }}
+draft: true
+---
+`
+
+// archetypeFuncs are the functions (out of Hugo's) that
+// archetypes can make use of.
+var archetypeFuncs = template.FuncMap{
+	"replace": func(input, old, new string) string {
+		return strings.Replace(input, old, new, -1)
+	},
+	"title": strings.Title,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"now":   time.Now,
+}
+
+// Archetype is a template that new content gets created from.
+type Archetype struct {
+	// Path is the path to the archetype file (or directory, for
+	// bundles), empty for Hugo's default archetype.
+	Path string
+
+	// Bundle indicates that the archetype is a page bundle: a
+	// directory whose files all get created.
+	Bundle bool
+}
+
+// ArchetypeData is what archetypes get rendered with.
+type ArchetypeData struct {
+	// Name is the name of the new page: the name of its file
+	// without extension, or the name of its bundle.
+	Name string
+
+	// Date is the time of creation, formatted as RFC3339.
+	Date string
+
+	// Type is the type of the new content: its section,
+	// unless some other kind is asked for.
+	Type string
+
+	// Section is the section that the new page belongs to.
+	Section string
+}
+
+// FindArchetype looks for the archetype that new content of a given
+// type (e.g., `posts`) and extension gets created from: a bundle at
+// `archetypes/<type>/`, then `archetypes/<type><ext>`, then the
+// default archetype of the site (`archetypes/default<ext>`), and
+// finally Hugo's default archetype.
+func (c *Config) FindArchetype(kind, ext string) (archetype *Archetype) {
+	dir := c.ArchetypeDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.Root, dir)
+	}
+
+	if kind != "" {
+		if path := filepath.Join(dir, kind); isDir(path) {
+			archetype = &Archetype{Path: path, Bundle: true}
+			return
+		}
+
+		if path := filepath.Join(dir, kind+ext); isFile(path) {
+			archetype = &Archetype{Path: path}
+			return
+		}
+	}
+
+	if path := filepath.Join(dir, "default"+ext); isFile(path) {
+		archetype = &Archetype{Path: path}
+		return
+	}
+
+	archetype = &Archetype{}
+	return
+}
+
+// NewContent creates new content at a given path (the page file, or
+// the directory of the bundle for bundle archetypes), retrieving the
+// files that got created.
+//
+// Content files get rendered as templates while other files of
+// bundles are copied as they are. Nothing that exists gets
+// overwritten.
+func (a *Archetype) NewContent(dest string, data ArchetypeData) (created []string, err error) {
+	if _, err = os.Stat(dest); err == nil {
+		err = errors.Errorf("%s already exists", dest)
+		return
+	}
+
+	if !a.Bundle {
+		var content = []byte(defaultArchetype)
+
+		if a.Path != "" {
+			content, err = ioutil.ReadFile(a.Path)
+			if err != nil {
+				err = errors.Wrapf(err,
+					"failed to read archetype %s", a.Path)
+				return
+			}
+		}
+
+		err = createFromArchetype(dest, a.Path, content, data, true)
+		if err != nil {
+			return
+		}
+
+		created = []string{dest}
+		return
+	}
+
+	err = filepath.Walk(a.Path, func(path string, info os.FileInfo, walkErr error) (err error) {
+		if walkErr != nil || info.IsDir() {
+			return walkErr
+		}
+
+		rel, err := filepath.Rel(a.Path, path)
+		if err != nil {
+			return
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to read archetype %s", path)
+			return
+		}
+
+		_, isContent := hugo.DefaultContentTypes[strings.ToLower(filepath.Ext(path))]
+
+		target := filepath.Join(dest, rel)
+		err = createFromArchetype(target, path, content, data, isContent)
+		if err != nil {
+			return
+		}
+
+		created = append(created, target)
+		return
+	})
+	return
+}
+
+// createFromArchetype creates a file with the contents of an
+// archetype, rendering them as a template if asked to.
+func createFromArchetype(dest, archetype string, content []byte, data ArchetypeData, render bool) (err error) {
+	if render {
+		var (
+			t   *template.Template
+			buf bytes.Buffer
+		)
+
+		t, err = template.New(filepath.Base(archetype)).Funcs(archetypeFuncs).Parse(string(content))
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to parse archetype %s", archetype)
+			return
+		}
+
+		err = t.Execute(&buf, data)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to render archetype %s", archetype)
+			return
+		}
+
+		content = buf.Bytes()
+	}
+
+	err = os.MkdirAll(filepath.Dir(dest), 0755)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create directory of %s", dest)
+		return
+	}
+
+	file, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create %s", dest)
+		return
+	}
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write %s", dest)
+		return
+	}
+
+	err = file.Close()
+	return
+}
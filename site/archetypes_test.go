@@ -0,0 +1,137 @@
+package site_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cirocosta/hugo-utils/site"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Archetypes", func() {
+	var (
+		config *site.Config
+		dest   string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		config, err = site.Load("testdata/archetype-site")
+		Expect(err).To(Succeed())
+
+		dest, err = ioutil.TempDir("", "hugo-utils-archetypes")
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dest)
+	})
+
+	read := func(path string) string {
+		content, err := ioutil.ReadFile(path)
+		Expect(err).To(Succeed())
+
+		return string(content)
+	}
+
+	Describe("FindArchetype", func() {
+		It("prefers bundles named after the type", func() {
+			Expect(config.FindArchetype("trip", ".md")).To(Equal(&site.Archetype{
+				Path:   "testdata/archetype-site/archetypes/trip",
+				Bundle: true,
+			}))
+		})
+
+		It("finds archetypes named after the type", func() {
+			Expect(config.FindArchetype("posts", ".md")).To(Equal(&site.Archetype{
+				Path: "testdata/archetype-site/archetypes/posts.md",
+			}))
+		})
+
+		It("falls back to the default archetype of the site", func() {
+			Expect(config.FindArchetype("notes", ".md")).To(Equal(&site.Archetype{
+				Path: "testdata/archetype-site/archetypes/default.md",
+			}))
+		})
+
+		It("falls back to hugo's default archetype", func() {
+			Expect(config.FindArchetype("notes", ".org")).To(Equal(&site.Archetype{}))
+		})
+	})
+
+	Describe("NewContent", func() {
+		data := site.ArchetypeData{
+			Name:    "my-first-post",
+			Date:    "2018-01-02T10:00:00Z",
+			Type:    "posts",
+			Section: "posts",
+		}
+
+		It("renders the archetype", func() {
+			path := filepath.Join(dest, "posts", "my-first-post.md")
+
+			created, err := config.FindArchetype("posts", ".md").NewContent(path, data)
+			Expect(err).To(Succeed())
+			Expect(created).To(Equal([]string{path}))
+			Expect(read(path)).To(Equal(`---
+title: "My First Post"
+date: 2018-01-02T10:00:00Z
+type: posts
+section: posts
+tags: []
+draft: true
+---
+`))
+		})
+
+		It("renders hugo's default archetype", func() {
+			path := filepath.Join(dest, "posts", "my-first-post.md")
+
+			_, err := (&site.Archetype{}).NewContent(path, data)
+			Expect(err).To(Succeed())
+			Expect(read(path)).To(Equal(`---
+title: "My First Post"
+date: 2018-01-02T10:00:00Z
+draft: true
+---
+`))
+		})
+
+		It("creates every file of bundles, rendering only content files", func() {
+			path := filepath.Join(dest, "trips", "lisbon")
+
+			data := site.ArchetypeData{Name: "lisbon", Type: "trip", Section: "trips"}
+			created, err := config.FindArchetype("trip", ".md").NewContent(path, data)
+			Expect(err).To(Succeed())
+			Expect(created).To(Equal([]string{
+				filepath.Join(path, "images", "cover.jpg"),
+				filepath.Join(path, "index.md"),
+				filepath.Join(path, "notes.txt"),
+			}))
+
+			Expect(read(filepath.Join(path, "index.md"))).To(Equal("---\ntitle: \"lisbon\"\ntype: trip\n---\n"))
+			Expect(read(filepath.Join(path, "notes.txt"))).To(Equal("notes about {{ .Name }}\n"))
+		})
+
+		It("refuses to overwrite existing content", func() {
+			path := filepath.Join(dest, "existing.md")
+			Expect(ioutil.WriteFile(path, []byte("mine"), 0644)).To(Succeed())
+
+			_, err := config.FindArchetype("posts", ".md").NewContent(path, data)
+			Expect(err).To(MatchError(ContainSubstring("already exists")))
+			Expect(read(path)).To(Equal("mine"))
+		})
+
+		It("refuses to overwrite existing bundles", func() {
+			path := filepath.Join(dest, "lisbon")
+			Expect(os.Mkdir(path, 0755)).To(Succeed())
+
+			_, err := config.FindArchetype("trip", ".md").NewContent(path, data)
+			Expect(err).To(MatchError(ContainSubstring("already exists")))
+		})
+	})
+})
@@ -0,0 +1,571 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/cirocosta/hugo-utils/hugo"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Config holds the settings of a Hugo site that matter when
+// dealing with its content.
+type Config struct {
+	// Root is the directory where the site lives.
+	Root string
+
+	// ContentDir is the directory (relative to the root,
+	// unless absolute) where the content lives.
+	ContentDir string
+
+	// ArchetypeDir is the directory (relative to the root,
+	// unless absolute) where the archetypes live.
+	ArchetypeDir string
+
+	// Taxonomies maps the singular name of each taxonomy
+	// to its plural (e.g., `tag` to `tags`).
+	Taxonomies map[string]string
+
+	// Permalinks maps sections to their permalink
+	// patterns (e.g., `posts` to `/:year/:slug/`).
+	Permalinks map[string]string
+
+	// Languages holds the languages of a multilingual
+	// site, keyed by their code.
+	Languages map[string]Language
+
+	// DefaultContentLanguage is the code of the language
+	// that content is written in unless specified.
+	DefaultContentLanguage string
+
+	// DefaultContentLanguageInSubdir indicates whether the
+	// default language gets its own URL prefix too.
+	DefaultContentLanguageInSubdir bool
+
+	// IgnoreFiles are the patterns of the content files
+	// that Hugo ignores.
+	IgnoreFiles []*regexp.Regexp
+
+	// FrontMatter indicates where each of the dates of a
+	// page comes from.
+	FrontMatter FrontMatterDates
+
+	// EnableGitInfo indicates whether the history of the git
+	// repository of the site is used for the dates of pages
+	// (`:git`).
+	EnableGitInfo bool
+
+	// Raw holds every setting of the site, merged from
+	// every configuration file, with lowercased keys.
+	Raw map[string]interface{}
+}
+
+// Language is one of the languages of a site.
+type Language struct {
+	Code       string
+	Name       string
+	Title      string
+	Weight     int
+	ContentDir string
+}
+
+// FrontMatterDates holds, for each of the dates of a page, the
+// ordered list of places where it can come from: front matter
+// keys (e.g., `publishDate`) or special values (`:filename`,
+// `:fileModTime` and `:git`).
+type FrontMatterDates struct {
+	Date        []string
+	LastMod     []string
+	PublishDate []string
+	ExpiryDate  []string
+}
+
+var (
+	// configFileNames are the names of the files that configure
+	// a site (in order of preference), without extension.
+	configFileNames = []string{"hugo", "config"}
+
+	// configFileExtensions are the formats that configuration
+	// files can be written in (in order of preference).
+	configFileExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+	// configDir is where settings can be split across files,
+	// relative to the site root.
+	configDir = filepath.Join("config", "_default")
+
+	// defaultFrontMatterDates are the places where Hugo looks
+	// for dates when the site doesn't configure them (or when
+	// `:default` is used).
+	defaultFrontMatterDates = FrontMatterDates(hugo.DefaultDateSources)
+)
+
+// ErrSiteNotFound indicates that no site could be found
+// in a directory or any of its parents.
+var ErrSiteNotFound = errors.New("no hugo site found")
+
+// FindRoot looks for the root of a site starting at a given
+// directory and walking up its parents until a directory that
+// has a site configuration is found.
+func FindRoot(start string) (root string, err error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to resolve absolute path of %s", start)
+		return
+	}
+
+	for {
+		if configFile(dir) != "" || isDir(filepath.Join(dir, configDir)) {
+			root = dir
+			return
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			err = ErrSiteNotFound
+			return
+		}
+
+		dir = parent
+	}
+}
+
+// Load loads the configuration of the site that lives at a
+// given root directory.
+//
+// Settings split across files under `config/_default` (where
+// `params.toml` holds the `params` setting and `menus.en.toml`
+// the `menus` of the `en` language) get merged with the ones of
+// the configuration file at the root (`hugo.toml`, `config.yaml`,
+// etc), which take precedence.
+func Load(root string) (config *Config, err error) {
+	var raw = map[string]interface{}{}
+
+	dirSettings, err := loadConfigDir(filepath.Join(root, configDir))
+	if err != nil {
+		return
+	}
+	mergeSettings(raw, dirSettings)
+
+	if file := configFile(root); file != "" {
+		var settings map[string]interface{}
+
+		settings, err = loadConfigFile(file)
+		if err != nil {
+			return
+		}
+
+		mergeSettings(raw, settings)
+	}
+
+	config, err = newConfig(root, raw)
+	return
+}
+
+// ContentPath retrieves the path to the content directory of
+// the site (or of a given language, if it has its own).
+func (c *Config) ContentPath(language string) string {
+	dir := c.ContentDir
+	if lang, ok := c.Languages[language]; ok && lang.ContentDir != "" {
+		dir = lang.ContentDir
+	}
+
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(c.Root, dir)
+}
+
+// ContentDirOf retrieves the content directory (of the site or of
+// one of its languages) that a directory lives in, or the one of the
+// default language if it's in none of them.
+//
+// When content directories are nested (e.g., `content` and
+// `content/en`), the most specific one is picked.
+func (c *Config) ContentDirOf(directory string) (contentDir string) {
+	contentDir = c.ContentPath(c.DefaultContentLanguage)
+
+	dir, err := filepath.Abs(directory)
+	if err != nil {
+		return
+	}
+
+	var (
+		candidates = []string{c.ContentPath("")}
+		longest    int
+	)
+
+	for code := range c.Languages {
+		candidates = append(candidates, c.ContentPath(code))
+	}
+
+	for _, candidate := range candidates {
+		abs, err := filepath.Abs(candidate)
+		if err != nil || len(abs) <= longest {
+			continue
+		}
+
+		rel, err := filepath.Rel(abs, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			contentDir, longest = candidate, len(abs)
+		}
+	}
+
+	return
+}
+
+// newConfig extracts the typed settings from the raw
+// settings of a site, filling in Hugo's defaults.
+func newConfig(root string, raw map[string]interface{}) (config *Config, err error) {
+	config = &Config{
+		Root:                   root,
+		ContentDir:             "content",
+		ArchetypeDir:           "archetypes",
+		Taxonomies:             map[string]string{"tag": "tags", "category": "categories"},
+		Permalinks:             map[string]string{},
+		Languages:              map[string]Language{},
+		DefaultContentLanguage: "en",
+		FrontMatter:            defaultFrontMatterDates,
+		Raw:                    raw,
+	}
+
+	if dir, ok := raw["contentdir"].(string); ok && dir != "" {
+		config.ContentDir = dir
+	}
+
+	if dir, ok := raw["archetypedir"].(string); ok && dir != "" {
+		config.ArchetypeDir = dir
+	}
+
+	if lang, ok := raw["defaultcontentlanguage"].(string); ok && lang != "" {
+		config.DefaultContentLanguage = lang
+	}
+
+	if inSubdir, ok := raw["defaultcontentlanguageinsubdir"].(bool); ok {
+		config.DefaultContentLanguageInSubdir = inSubdir
+	}
+
+	if enabled, ok := raw["enablegitinfo"].(bool); ok {
+		config.EnableGitInfo = enabled
+	}
+
+	if taxonomies, ok := raw["taxonomies"].(map[string]interface{}); ok {
+		// defining taxonomies replaces the default ones.
+		config.Taxonomies = stringSettings(taxonomies)
+	}
+
+	if permalinks, ok := raw["permalinks"].(map[string]interface{}); ok {
+		config.Permalinks = stringSettings(permalinks)
+	}
+
+	if languages, ok := raw["languages"].(map[string]interface{}); ok {
+		for code, settings := range languages {
+			config.Languages[code] = newLanguage(code, settings)
+		}
+	}
+
+	if patterns, ok := raw["ignorefiles"].([]interface{}); ok {
+		for _, pattern := range patterns {
+			var re *regexp.Regexp
+
+			re, err = regexp.Compile(fmt.Sprint(pattern))
+			if err != nil {
+				err = errors.Wrapf(err,
+					"invalid ignoreFiles pattern %v", pattern)
+				return
+			}
+
+			config.IgnoreFiles = append(config.IgnoreFiles, re)
+		}
+	}
+
+	if dates, ok := raw["frontmatter"].(map[string]interface{}); ok {
+		for key, field := range map[string]*[]string{
+			"date":        &config.FrontMatter.Date,
+			"lastmod":     &config.FrontMatter.LastMod,
+			"publishdate": &config.FrontMatter.PublishDate,
+			"expirydate":  &config.FrontMatter.ExpiryDate,
+		} {
+			if sources, ok := dates[key].([]interface{}); ok {
+				*field = dateSources(sources, *field)
+			}
+		}
+	}
+
+	return
+}
+
+// newLanguage extracts the settings of a language.
+func newLanguage(code string, settings interface{}) (lang Language) {
+	lang.Code = code
+
+	values, ok := settings.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	lang.Name, _ = values["languagename"].(string)
+	lang.Title, _ = values["title"].(string)
+	lang.ContentDir, _ = values["contentdir"].(string)
+
+	switch weight := values["weight"].(type) {
+	case int:
+		lang.Weight = weight
+	case int64:
+		lang.Weight = int(weight)
+	case float64:
+		lang.Weight = int(weight)
+	}
+
+	return
+}
+
+// dateSources lowercases the front matter keys that a date can
+// come from, expanding `:default` into Hugo's default sources.
+func dateSources(sources []interface{}, defaults []string) (res []string) {
+	for _, source := range sources {
+		value := strings.ToLower(fmt.Sprint(source))
+
+		switch value {
+		case ":default":
+			res = append(res, defaults...)
+		case ":filemodtime":
+			res = append(res, ":fileModTime")
+		default:
+			res = append(res, value)
+		}
+	}
+
+	return
+}
+
+// stringSettings converts a table of settings whose
+// values are strings.
+func stringSettings(settings map[string]interface{}) (res map[string]string) {
+	res = map[string]string{}
+	for key, value := range settings {
+		res[key] = fmt.Sprint(value)
+	}
+
+	return
+}
+
+// configFile retrieves the configuration file that lives in
+// a directory (if any).
+func configFile(dir string) string {
+	for _, name := range configFileNames {
+		for _, ext := range configFileExtensions {
+			path := filepath.Join(dir, name+ext)
+
+			info, err := os.Stat(path)
+			if err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+// loadConfigDir loads the settings that are split across the
+// files of a configuration directory (if it exists).
+func loadConfigDir(dir string) (settings map[string]interface{}, err error) {
+	settings = map[string]interface{}{}
+
+	if !isDir(dir) {
+		return
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to read configuration directory %s", dir)
+		return
+	}
+
+	// settings of the `config` and `hugo` files come last
+	// so that they take precedence.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return !isRootConfigFile(entries[i].Name()) && isRootConfigFile(entries[j].Name())
+	})
+
+	for _, entry := range entries {
+		var (
+			name = entry.Name()
+			ext  = filepath.Ext(name)
+			file map[string]interface{}
+		)
+
+		if entry.IsDir() || !isConfigFileExtension(ext) {
+			continue
+		}
+
+		file, err = loadConfigFile(filepath.Join(dir, name))
+		if err != nil {
+			return
+		}
+
+		var (
+			key      = strings.ToLower(strings.TrimSuffix(name, ext))
+			parts    = strings.SplitN(key, ".", 2)
+			fileKeys map[string]interface{}
+		)
+
+		switch {
+		case isRootConfigFile(name):
+			fileKeys = file
+		case len(parts) == 2:
+			// e.g., `menus.en.toml` holds the
+			// `menus` of the `en` language.
+			fileKeys = map[string]interface{}{
+				"languages": map[string]interface{}{
+					parts[1]: map[string]interface{}{
+						parts[0]: file,
+					},
+				},
+			}
+		default:
+			fileKeys = map[string]interface{}{key: file}
+		}
+
+		mergeSettings(settings, fileKeys)
+	}
+
+	return
+}
+
+// loadConfigFile parses a configuration file according to
+// its extension, lowercasing every key.
+func loadConfigFile(path string) (settings map[string]interface{}, err error) {
+	var raw map[string]interface{}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to read configuration file %s", path)
+		return
+	}
+
+	switch filepath.Ext(path) {
+	case ".toml":
+		_, err = toml.Decode(string(content), &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".json":
+		err = json.Unmarshal(content, &raw)
+	default:
+		err = errors.Errorf("unknown configuration format")
+	}
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to parse configuration file %s", path)
+		return
+	}
+
+	settings, _ = normalizeSettings(raw).(map[string]interface{})
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+
+	return
+}
+
+// normalizeSettings converts the tables of a configuration
+// (whatever their decoder produced) into maps with lowercased
+// string keys, as Hugo treats keys case-insensitively.
+func normalizeSettings(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			res[strings.ToLower(key)] = normalizeSettings(item)
+		}
+
+		return res
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			res[strings.ToLower(fmt.Sprint(key))] = normalizeSettings(item)
+		}
+
+		return res
+	case []map[string]interface{}:
+		res := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			res = append(res, normalizeSettings(item))
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			res = append(res, normalizeSettings(item))
+		}
+
+		return res
+	default:
+		return value
+	}
+}
+
+// mergeSettings merges `src` into `dst`, with the values of
+// `src` taking precedence (tables get merged recursively).
+func mergeSettings(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcTable, srcOk := value.(map[string]interface{})
+		dstTable, dstOk := dst[key].(map[string]interface{})
+
+		if srcOk && dstOk {
+			mergeSettings(dstTable, srcTable)
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+// isRootConfigFile indicates whether a file of a configuration
+// directory holds settings of the top level.
+func isRootConfigFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	for _, configName := range configFileNames {
+		if base == configName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isConfigFileExtension indicates whether configuration files
+// can be written in the format of a given extension.
+func isConfigFileExtension(ext string) bool {
+	for _, configExt := range configFileExtensions {
+		if ext == configExt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDir indicates whether a path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// isFile indicates whether a path exists and is a regular file.
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,210 @@
+package site_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cirocosta/hugo-utils/site"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config", func() {
+	var (
+		config *site.Config
+		err    error
+	)
+
+	Describe("FindRoot", func() {
+		It("finds the site from a nested directory", func() {
+			root, err := site.FindRoot("testdata/toml-site/articles/posts")
+			Expect(err).To(Succeed())
+
+			expected, err := filepath.Abs("testdata/toml-site")
+			Expect(err).To(Succeed())
+			Expect(root).To(Equal(expected))
+		})
+
+		It("finds sites with only a configuration directory", func() {
+			dir, err := ioutil.TempDir("", "")
+			Expect(err).To(Succeed())
+			defer os.RemoveAll(dir)
+
+			Expect(os.MkdirAll(filepath.Join(dir, "config", "_default"), 0755)).To(Succeed())
+
+			root, err := site.FindRoot(dir)
+			Expect(err).To(Succeed())
+			Expect(root).To(Equal(dir))
+		})
+
+		It("fails without a site", func() {
+			dir, err := ioutil.TempDir("", "")
+			Expect(err).To(Succeed())
+			defer os.RemoveAll(dir)
+
+			_, err = site.FindRoot(dir)
+			Expect(err).To(Equal(site.ErrSiteNotFound))
+		})
+	})
+
+	Context("with toml configuration", func() {
+		BeforeEach(func() {
+			config, err = site.Load("testdata/toml-site")
+			Expect(err).To(Succeed())
+		})
+
+		It("has the content directory", func() {
+			Expect(config.ContentDir).To(Equal("articles"))
+			Expect(config.ContentPath("")).To(Equal("testdata/toml-site/articles"))
+		})
+
+		It("has the taxonomies replacing the default ones", func() {
+			Expect(config.Taxonomies).To(Equal(map[string]string{
+				"tag":    "tags",
+				"series": "series",
+				"author": "authors",
+			}))
+		})
+
+		It("has the permalinks", func() {
+			Expect(config.Permalinks).To(Equal(map[string]string{
+				"posts": "/:year/:month/:slug/",
+			}))
+		})
+
+		It("has the ignored files", func() {
+			Expect(config.IgnoreFiles).To(HaveLen(2))
+			Expect(config.IgnoreFiles[0].MatchString("posts/a.draft.md")).To(BeTrue())
+		})
+
+		It("has the front matter dates with defaults expanded", func() {
+			Expect(config.FrontMatter.Date).To(Equal([]string{
+				"date", ":filename", "date", "publishdate", "lastmod",
+			}))
+			Expect(config.FrontMatter.LastMod).To(Equal([]string{
+				":fileModTime", "lastmod",
+			}))
+			Expect(config.FrontMatter.PublishDate).To(Equal([]string{
+				"publishdate", "date",
+			}))
+		})
+
+		It("has git info enabled", func() {
+			Expect(config.EnableGitInfo).To(BeTrue())
+		})
+
+		It("keeps every setting with lowercased keys", func() {
+			Expect(config.Raw["baseurl"]).To(Equal("https://example.com/"))
+		})
+	})
+
+	Context("with yaml configuration", func() {
+		BeforeEach(func() {
+			config, err = site.Load("testdata/yaml-site")
+			Expect(err).To(Succeed())
+		})
+
+		It("has hugo's defaults", func() {
+			Expect(config.ContentDir).To(Equal("content"))
+			Expect(config.Taxonomies).To(Equal(map[string]string{
+				"tag":      "tags",
+				"category": "categories",
+			}))
+			Expect(config.FrontMatter.ExpiryDate).To(Equal([]string{"expirydate"}))
+			Expect(config.EnableGitInfo).To(BeFalse())
+		})
+
+		It("has the languages", func() {
+			Expect(config.DefaultContentLanguage).To(Equal("pt"))
+			Expect(config.DefaultContentLanguageInSubdir).To(BeTrue())
+			Expect(config.Languages).To(Equal(map[string]site.Language{
+				"pt": {Code: "pt", Name: "Português", Weight: 1, ContentDir: "content/pt"},
+				"en": {Code: "en", Name: "English", Weight: 2, ContentDir: "content/en"},
+			}))
+			Expect(config.ContentPath("en")).To(Equal("testdata/yaml-site/content/en"))
+		})
+	})
+
+	Context("with nested content directories", func() {
+		BeforeEach(func() {
+			config, err = site.Load("testdata/nested-site")
+			Expect(err).To(Succeed())
+		})
+
+		It("picks the most specific content directory", func() {
+			for i := 0; i < 10; i++ {
+				Expect(config.ContentDirOf("testdata/nested-site/content/pt/posts")).
+					To(Equal("testdata/nested-site/content/pt"))
+				Expect(config.ContentDirOf("testdata/nested-site/content/pt")).
+					To(Equal("testdata/nested-site/content/pt"))
+			}
+		})
+
+		It("picks the outer content directory for what's outside the inner one", func() {
+			Expect(config.ContentDirOf("testdata/nested-site/content/posts")).
+				To(Equal("testdata/nested-site/content"))
+			Expect(config.ContentDirOf("testdata/nested-site/content/ptx")).
+				To(Equal("testdata/nested-site/content"))
+		})
+
+		It("picks the one of the default language for directories out of the content", func() {
+			Expect(config.ContentDirOf("testdata/nested-site")).
+				To(Equal("testdata/nested-site/content/pt"))
+		})
+	})
+
+	Context("with json configuration", func() {
+		It("has the permalinks", func() {
+			config, err = site.Load("testdata/json-site")
+			Expect(err).To(Succeed())
+			Expect(config.Permalinks).To(Equal(map[string]string{
+				"blog": "/blog/:slug/",
+			}))
+		})
+	})
+
+	Context("with configuration split across files", func() {
+		BeforeEach(func() {
+			config, err = site.Load("testdata/split-site")
+			Expect(err).To(Succeed())
+		})
+
+		It("lets the root configuration file take precedence", func() {
+			Expect(config.ContentDir).To(Equal("from-root"))
+			Expect(config.Raw["title"]).To(Equal("split site"))
+		})
+
+		It("puts the settings of each file under its key", func() {
+			Expect(config.Raw["params"]).To(Equal(map[string]interface{}{
+				"author": "someone",
+			}))
+			Expect(config.Taxonomies).To(Equal(map[string]string{
+				"series": "series",
+			}))
+		})
+
+		It("puts language-specific files under their language", func() {
+			Expect(config.Languages).To(HaveKey("en"))
+			Expect(config.Languages["en"].Title).To(Equal("English site"))
+
+			languages := config.Raw["languages"].(map[string]interface{})
+			Expect(languages["en"]).To(HaveKey("menus"))
+		})
+	})
+
+	Context("with a broken configuration", func() {
+		It("fails", func() {
+			dir, err := ioutil.TempDir("", "")
+			Expect(err).To(Succeed())
+			defer os.RemoveAll(dir)
+
+			Expect(ioutil.WriteFile(
+				filepath.Join(dir, "config.toml"), []byte("title = "), 0644)).To(Succeed())
+
+			_, err = site.Load(dir)
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})